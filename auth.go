@@ -0,0 +1,127 @@
+package neo4j
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+/*
+AuthToken(token string) returns an Option making every request authenticate with token instead of user and password
+*/
+func AuthToken(token string) Option {
+	return func(neo *Neo4j) error {
+		neo.Token = token
+		return nil
+	}
+}
+
+// root of the server, the user endpoints live outside of /db/data
+func (this *Neo4j) serverURL() string {
+	return strings.TrimSuffix(strings.TrimRight(this.URL, "/"), "/db/data")
+}
+
+/*
+PasswordChangeRequired(user string) returns whether the password of user has to be changed before the server accepts other requests and any errors raised as error
+true for the default neo4j user on a freshly installed server
+*/
+func (this *Neo4j) PasswordChangeRequired(user string) (bool, error) {
+	if len(user) < 1 {
+		return false, errors.New("User must be at least 1 character.")
+	}
+	body, resp, err := this.send("Neo4j.PasswordChangeRequired", "GET", this.serverURL()+"/user/"+url.PathEscape(user), "")
+	if err != nil {
+		return false, err
+	}
+	errorList := map[int]error{
+		401: errors.New("Invalid username or password."),
+		404: errors.New("User not found."),
+	}
+	err = this.statusError("Neo4j.PasswordChangeRequired", errorList, resp, body)
+	if err != nil {
+		return false, err
+	}
+	state := struct {
+		Required bool `json:"password_change_required"`
+	}{}
+	err = json.Unmarshal([]byte(body), &state)
+	if err != nil {
+		return false, err
+	}
+	return state.Required, nil
+}
+
+/*
+ChangePassword(user string, password string) returns any errors raised as error
+sets a new password for user, the client switches to it when user is the one it authenticates as
+the switch applies to every copy of the client, ie: made by WithContext, and is safe while requests are in flight. clients not made by NewNeo4j keep their credentials
+*/
+func (this *Neo4j) ChangePassword(user string, password string) error {
+	if len(user) < 1 || len(password) < 1 {
+		return errors.New("User and password must be at least 1 character.")
+	}
+	s, err := json.Marshal(map[string]string{"password": password})
+	if err != nil {
+		return errors.New("Unable to Marshal Json data")
+	}
+	body, resp, err := this.send("Neo4j.ChangePassword", "POST", this.serverURL()+"/user/"+url.PathEscape(user)+"/password", string(s))
+	if err != nil {
+		return err
+	}
+	errorList := map[int]error{
+		400: errors.New("Invalid password."),
+		401: errors.New("Invalid username or password."),
+		422: errors.New("New password must differ from the old one."),
+	}
+	err = this.statusError("Neo4j.ChangePassword", errorList, resp, body)
+	if err != nil || this.rotated == nil {
+		return err
+	}
+	token := struct {
+		Token string `json:"authorization_token"`
+	}{}
+	json.Unmarshal([]byte(body), &token) // servers handing out tokens send the new one along
+	this.rotated.mu.Lock()
+	defer this.rotated.mu.Unlock()
+	if user == this.Username {
+		this.rotated.user, this.rotated.password = user, password
+	}
+	if len(token.Token) > 0 && len(this.Token) > 0 {
+		this.rotated.token = token.Token
+	}
+	return nil
+}
+
+// credentials set by ChangePassword, shared by every copy of the client so they all switch at once
+// the exported Username, Password and Token fields are never written after the client is configured, so reading them doesn't race
+type rotatedAuth struct {
+	mu       sync.RWMutex
+	user     string // the password below belongs to it
+	password string
+	token    string
+}
+
+// the credentials requests authenticate with: the configured ones unless ChangePassword replaced them
+func (this *Neo4j) credentials() (user string, password string, token string) {
+	user, password, token = this.Username, this.Password, this.Token
+	if this.rotated == nil {
+		return
+	}
+	this.rotated.mu.RLock()
+	defer this.rotated.mu.RUnlock()
+	if len(this.rotated.password) > 0 && this.rotated.user == user {
+		password = this.rotated.password
+	}
+	if len(this.rotated.token) > 0 && len(token) > 0 {
+		token = this.rotated.token
+	}
+	return
+}
+
+// Authorization header value for token auth
+func tokenHeader(token string) string {
+	return `Basic realm="Neo4j" ` + base64.StdEncoding.EncodeToString([]byte(":"+token))
+}
@@ -0,0 +1,93 @@
+package neo4j
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// base url of the node or relationship auto index
+func (this *Neo4j) autoIdxURL(idxType string) string {
+	if strings.ToLower(idxType) == "relationship" {
+		return this.endpoint("relationship_auto_index", "/index/auto/relationship")
+	}
+	return this.endpoint("node_auto_index", "/index/auto/node")
+}
+
+/*
+GetAutoIdxStatus(index type string) returns whether auto indexing is enabled and any errors raised as error
+*/
+func (this *Neo4j) GetAutoIdxStatus(idxType string) (bool, error) {
+	body, resp, err := this.send("Neo4j.GetAutoIdxStatus", "GET", this.autoIdxURL(idxType)+"/status", "")
+	if err != nil {
+		return false, err
+	}
+	err = this.statusError("Neo4j.GetAutoIdxStatus", map[int]error{}, resp, body)
+	if err != nil || this.ignored(resp.StatusCode) {
+		return false, err
+	}
+	return strconv.ParseBool(strings.TrimSpace(body))
+}
+
+/*
+SetAutoIdxStatus(index type string, enabled bool) returns any errors raised as error
+*/
+func (this *Neo4j) SetAutoIdxStatus(idxType string, enabled bool) error {
+	body, resp, err := this.send("Neo4j.SetAutoIdxStatus", "PUT", this.autoIdxURL(idxType)+"/status", strconv.FormatBool(enabled))
+	if err != nil {
+		return err
+	}
+	errorList := map[int]error{
+		400: errors.New("Invalid data sent."),
+	}
+	return this.statusError("Neo4j.SetAutoIdxStatus", errorList, resp, body)
+}
+
+/*
+GetAutoIdxProperties(index type string) returns the auto indexed property names and any errors raised as error
+*/
+func (this *Neo4j) GetAutoIdxProperties(idxType string) ([]string, error) {
+	return this.getStrings("Neo4j.GetAutoIdxProperties", this.autoIdxURL(idxType)+"/properties", map[int]error{})
+}
+
+/*
+AddAutoIdxProperty(index type string, name string) returns any errors raised as error
+*/
+func (this *Neo4j) AddAutoIdxProperty(idxType string, name string) error {
+	name = strings.TrimSpace(name)
+	if len(name) < 1 {
+		return errors.New("Property name must be at least 1 character.")
+	}
+	s, err := json.Marshal(name)
+	if err != nil {
+		return errors.New("Unable to Marshal Json data")
+	}
+	body, resp, err := this.send("Neo4j.AddAutoIdxProperty", "POST", this.autoIdxURL(idxType)+"/properties", string(s))
+	if err != nil {
+		return err
+	}
+	errorList := map[int]error{
+		400: errors.New("Invalid data sent."),
+	}
+	return this.statusError("Neo4j.AddAutoIdxProperty", errorList, resp, body)
+}
+
+/*
+RemoveAutoIdxProperty(index type string, name string) returns any errors raised as error
+*/
+func (this *Neo4j) RemoveAutoIdxProperty(idxType string, name string) error {
+	name = strings.TrimSpace(name)
+	if len(name) < 1 {
+		return errors.New("Property name must be at least 1 character.")
+	}
+	body, resp, err := this.send("Neo4j.RemoveAutoIdxProperty", "DELETE", this.autoIdxURL(idxType)+"/properties/"+url.PathEscape(name), "")
+	if err != nil {
+		return err
+	}
+	errorList := map[int]error{
+		404: errors.New("Property not auto indexed."),
+	}
+	return this.statusError("Neo4j.RemoveAutoIdxProperty", errorList, resp, body)
+}
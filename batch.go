@@ -0,0 +1,497 @@
+package neo4j
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// a single operation sent to the neo4j batch endpoint
+type BatchJob struct {
+	Method string      `json:"method"`
+	To     string      `json:"to"`             // relative path, full URL or a "{id}" reference to an earlier job
+	Body   interface{} `json:"body,omitempty"` // anything json.Marshal can handle
+	ID     int         `json:"id"`
+}
+
+// result of a single BatchJob as returned from neo4j
+type BatchResult struct {
+	ID       int
+	Location string          // set when the job created something
+	Status   int             // not sent by older servers
+	From     string          // the "to" of the job this result belongs to
+	Body     json.RawMessage // raw json body of the job
+	Data     []*NeoTemplate  // Body unmarshaled into NeoTemplate structs when it holds nodes/relationships
+	Err      error           // *ServerError of the job when its status is 4xx or 5xx
+}
+
+// returned by Batch when jobs of it failed, Results holds the result of every job with Err set on the failed ones
+type BatchError struct {
+	Results map[int]*BatchResult
+}
+
+func (this *BatchError) Error() string {
+	errs := this.failed()
+	msgs := make([]string, len(errs))
+	for i, result := range errs {
+		msgs[i] = "job " + strconv.Itoa(result.ID) + ": " + result.Err.Error()
+	}
+	return strconv.Itoa(len(errs)) + " of the batch jobs failed: " + strings.Join(msgs, "; ")
+}
+
+// makes errors.Is and errors.As look at the error of every failed job
+func (this *BatchError) Unwrap() []error {
+	errs := this.failed()
+	list := make([]error, len(errs))
+	for i, result := range errs {
+		list[i] = result.Err
+	}
+	return list
+}
+
+// the results carrying an error, in job order
+func (this *BatchError) failed() []*BatchResult {
+	list := []*BatchResult{}
+	for _, result := range this.Results {
+		if result.Err != nil {
+			list = append(list, result)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+// refers to a node or relationship inside a Session, either an existing one or one still pending creation
+type Ref string
+
+// unit of work: creates, updates and deletes are recorded in memory and sent to neo4j as a single batch on Flush()
+// neo4j runs a batch inside one transaction so either every change is applied or none of them are
+type Session struct {
+	neo  *Neo4j
+	jobs []*BatchJob
+	err  error // first error recording a job, returned by Flush
+}
+
+/*
+Batch(jobs []*BatchJob) returns a map of BatchResult structs keyed by job id and any errors raised as error
+jobs without an id are numbered by their position, or above the largest id set when another job already has that one. ids set twice are an error
+when the server reports jobs as failed the results are returned along with a *BatchError, the Err of each failed result says why
+*/
+func (this *Neo4j) Batch(jobs []*BatchJob) (map[int]*BatchResult, error) {
+	return this.batch("Neo4j.Batch", jobs)
+}
+
+// sends the jobs for Batch, op is the client method they are sent for
+func (this *Neo4j) batch(op string, jobs []*BatchJob) (map[int]*BatchResult, error) {
+	err := numberJobs(jobs)
+	if err != nil {
+		return nil, err
+	}
+	for _, job := range jobs {
+		job.Method = strings.ToUpper(job.Method)
+	}
+	s, err := marshal(jobs)
+	if err != nil {
+		return nil, errors.New("Unable to Marshal Json data")
+	}
+	errorList := map[int]error{
+		400: errors.New("Invalid data sent."),
+	}
+	if this.metrics != nil {
+		this.metrics.Transactions(1)
+		defer this.metrics.Transactions(-1)
+	}
+	var raw []batchResponse
+	err = this.receive(op, "POST", this.endpoint("batch", "/batch"), s, errorList, &raw)
+	if err != nil {
+		return nil, err
+	}
+	results := this.unmarshalBatch(op, raw, jobs)
+	for _, result := range results {
+		if result.Err != nil {
+			return results, &BatchError{results}
+		}
+	}
+	return results, nil
+}
+
+// gives the jobs without an id their position, unless another job set that id already, then the next one above every id in use
+// {n} references are resolved by id, so no two jobs may share one
+func numberJobs(jobs []*BatchJob) error {
+	used := map[int]bool{}
+	max := 0
+	for _, job := range jobs {
+		if job.ID == 0 {
+			continue
+		}
+		if used[job.ID] {
+			return errors.New("Batch job id " + strconv.Itoa(job.ID) + " is used more than once.")
+		}
+		used[job.ID] = true
+		if job.ID > max {
+			max = job.ID
+		}
+	}
+	for i, job := range jobs {
+		if job.ID != 0 {
+			continue
+		}
+		id := i
+		if used[id] {
+			max++
+			id = max
+		}
+		used[id] = true
+		if id > max {
+			max = id
+		}
+		job.ID = id
+	}
+	return nil
+}
+
+// a single element of the json array returned from the batch endpoint
+type batchResponse struct {
+	ID       int             `json:"id"`
+	Location string          `json:"location"`
+	Status   int             `json:"status"`
+	From     string          `json:"from"`
+	Body     json.RawMessage `json:"body"`
+}
+
+// unpacks the json array returned from the batch endpoint
+func (this *Neo4j) unmarshalBatch(op string, raw []batchResponse, jobs []*BatchJob) map[int]*BatchResult {
+	methods := make(map[int]string, len(jobs))
+	for _, job := range jobs {
+		methods[job.ID] = job.Method
+	}
+	results := make(map[int]*BatchResult)
+	for _, r := range raw {
+		result := &BatchResult{ID: r.ID, Location: r.Location, Status: r.Status, From: r.From, Body: r.Body}
+		if r.Status >= 400 {
+			e := newServerError(r.Status, r.Body, statusSentinel(r.Status))
+			e.Op, e.Method, e.URL = op, methods[r.ID], r.From
+			result.Err = constraintViolation(e)
+		}
+		b := strings.TrimSpace(string(r.Body))
+		if strings.HasPrefix(b, "{") || strings.HasPrefix(b, "[{") {
+			result.Data, _ = this.unmarshal(b) // not every body holds nodes/relationships, Body is still there when this fails
+		}
+		results[r.ID] = result
+	}
+	return results
+}
+
+/*
+NewSession() returns an empty Session bound to this client
+*/
+func (this *Neo4j) NewSession() *Session {
+	return &Session{neo: this}
+}
+
+// keeps the first error
+func (this *Session) fail(err error) {
+	if this.err == nil {
+		this.err = err
+	}
+}
+
+// queues a job and returns its id
+func (this *Session) add(method string, to string, body interface{}) int {
+	id := len(this.jobs)
+	this.jobs = append(this.jobs, &BatchJob{Method: method, To: to, Body: body, ID: id})
+	return id
+}
+
+/*
+Node(node id uint) returns a Ref to an existing node
+*/
+func (this *Session) Node(id NodeID) Ref {
+	return Ref(this.neo.endpoint("node", "/node") + "/" + id.String())
+}
+
+/*
+Relationship(relationship id uint) returns a Ref to an existing relationship
+*/
+func (this *Session) Relationship(id RelID) Ref {
+	return Ref(this.neo.endpoint("relationship", "/relationship") + "/" + id.String())
+}
+
+/*
+CreateNode(data map[string]string) returns a Ref to the pending node
+a node failing validation, see RegisterValidator, makes Flush return the error without sending anything
+*/
+func (this *Session) CreateNode(data map[string]string) Ref {
+	return this.CreateNodeTyped(typedProperties(data))
+}
+
+/*
+CreateNodeTyped(data map[string]interface{}) returns a Ref to the pending node
+same as CreateNode but values keep their json type
+*/
+func (this *Session) CreateNodeTyped(data map[string]interface{}) Ref {
+	err := this.neo.validate(data)
+	if err != nil {
+		this.fail(err)
+	}
+	data, err = this.neo.encodeProperties(data)
+	if err != nil {
+		this.fail(err)
+	}
+	body, err := this.neo.newNodeProperties(data)
+	if err != nil {
+		this.fail(err)
+	}
+	id := this.add("POST", "/node", body)
+	return Ref("{" + strconv.Itoa(id) + "}")
+}
+
+/*
+CreateRelationship(src Ref, dst Ref, data map[string]string, relationship type string) returns a Ref to the pending relationship
+*/
+func (this *Session) CreateRelationship(src Ref, dst Ref, data map[string]string, rType string) Ref {
+	return this.CreateRelationshipTyped(src, dst, typedProperties(data), rType)
+}
+
+/*
+CreateRelationshipTyped(src Ref, dst Ref, data map[string]interface{}, relationship type string) returns a Ref to the pending relationship
+same as CreateRelationship but values keep their json type
+*/
+func (this *Session) CreateRelationshipTyped(src Ref, dst Ref, data map[string]interface{}, rType string) Ref {
+	data, err := this.neo.encodeProperties(data)
+	if err != nil {
+		this.fail(err)
+	}
+	j := map[string]interface{}{} // empty map: keys are always strings in json, values vary
+	j["to"] = string(dst)
+	j["type"] = rType
+	j["data"] = data
+	id := this.add("POST", string(src)+"/relationships", j)
+	return Ref("{" + strconv.Itoa(id) + "}")
+}
+
+/*
+SetProperty(ref Ref, data map[string]string, replace bool)
+works for both nodes and relationships, see Neo4j.SetProperty for the meaning of replace
+only the global validators run, the labels of a Ref aren't known inside the session. see RegisterValidator
+*/
+func (this *Session) SetProperty(ref Ref, data map[string]string, replace bool) {
+	err := this.neo.validate(typedProperties(data))
+	if err != nil {
+		this.fail(err)
+	}
+	if replace {
+		this.add("PUT", string(ref)+"/properties", data)
+		return
+	}
+	for k, v := range data {
+		this.add("PUT", string(ref)+"/properties/"+strings.TrimSpace(k), v)
+	}
+}
+
+/*
+DelProperty(ref Ref, name string)
+*/
+func (this *Session) DelProperty(ref Ref, name string) {
+	this.add("DELETE", string(ref)+"/properties/"+name, nil)
+}
+
+/*
+Delete(ref Ref)
+deletes a node or relationship. nodes must not have relationships left once the batch reaches this point
+*/
+func (this *Session) Delete(ref Ref) {
+	this.add("DELETE", string(ref), nil)
+}
+
+/*
+Len() returns the number of pending jobs
+*/
+func (this *Session) Len() int {
+	return len(this.jobs)
+}
+
+/*
+Flush() returns a map of BatchResult structs keyed by job id and any errors raised as error
+the session is emptied when the batch was sent successfully, on error it keeps its jobs so it can be retried
+the results come along with a *BatchError when the server reported jobs as failed
+nothing is sent when recording a job failed, ie: no UUID could be generated for a node, the error is returned instead
+*/
+func (this *Session) Flush() (map[int]*BatchResult, error) {
+	return this.flush("Session.Flush")
+}
+
+// sends the jobs for Flush, op is the client method they are sent for
+func (this *Session) flush(op string) (map[int]*BatchResult, error) {
+	if this.err != nil {
+		return nil, this.err
+	}
+	if len(this.jobs) < 1 {
+		return map[int]*BatchResult{}, nil
+	}
+	results, err := this.neo.batch(op, this.jobs)
+	if err != nil {
+		return results, err // set along with a *BatchError
+	}
+	this.jobs = nil
+	return results, nil
+}
+
+/*
+Transact(fn func(*Session) error) returns a map of BatchResult structs keyed by job id and any errors raised as error
+fn records the changes on a new Session which is then flushed as a single transaction
+when neo4j aborts the transaction on a deadlock fn runs again on a fresh Session after a short wait, see DeadlockRetry. fn must not have other side effects for that
+an error returned by fn ends it without sending anything
+*/
+func (this *Neo4j) Transact(fn func(*Session) error) (map[int]*BatchResult, error) {
+	policy := this.deadlock
+	if policy == nil {
+		policy = defaultDeadlockRetry
+	}
+	for n := 1; ; n++ {
+		session := this.NewSession()
+		err := fn(session)
+		if err != nil {
+			return nil, err
+		}
+		results, err := session.flush("Neo4j.Transact")
+		if err == nil || !isDeadlock(err) || n >= policy.attempts {
+			return results, err
+		}
+		this.log().Info("neo4j: transaction deadlocked, running it again", "attempt", n+1)
+		this.observeRetry("Neo4j.Transact", "POST")
+		err = policy.wait(this.Context(), n)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+/*
+CloneNode(node id uint, includeRelationships bool) returns a NeoTemplate struct of the copy and any errors raised as error
+copies the properties and labels of the node, and with includeRelationships every relationship on it, in a single batch
+the UUID of the node isn't copied, the copy gets a new one. see UseUUIDs
+*/
+func (this *Neo4j) CloneNode(id NodeID, includeRelationships bool) (tmp *NeoTemplate, err error) {
+	node, err := this.GetNode(id)
+	if err != nil {
+		return tmp, err
+	}
+	labels, err := this.GetLabels(id)
+	if err != nil {
+		return tmp, err
+	}
+	var rels []*NeoTemplate
+	if includeRelationships {
+		rels, err = this.GetRelationshipsOnNode(id, "", DirAll)
+		if err != nil {
+			return tmp, err
+		}
+	}
+	session := this.NewSession()
+	data := map[string]interface{}{}
+	for k, v := range node.Data {
+		if k != this.UUIDProperty || len(k) < 1 { // the copy is a node of its own and gets a UUID of its own
+			data[k] = v
+		}
+	}
+	err = this.validate(data)
+	if err != nil {
+		return tmp, err
+	}
+	err = this.validateLabels(labels, data)
+	if err != nil {
+		return tmp, err
+	}
+	data, err = this.newNodeProperties(data)
+	if err != nil {
+		return tmp, err
+	}
+	clone := Ref("{" + strconv.Itoa(session.add("POST", "/node", data)) + "}") // keeps the property types, unlike Session.CreateNode
+	if len(labels) > 0 {
+		session.add("POST", string(clone)+"/labels", labels)
+	}
+	for i := 0; i < len(rels); i++ {
+		rel, err := rels[i].Relationship()
+		if err != nil {
+			return tmp, err
+		}
+		src, dst := Ref(rel.Start), Ref(rel.End)
+		if rel.StartID == id {
+			src = clone
+		}
+		if rel.EndID == id {
+			dst = clone
+		}
+		j := map[string]interface{}{} // empty map: keys are always strings in json, values vary
+		j["to"] = string(dst)
+		j["type"] = rel.Type
+		if rel.Data != nil {
+			j["data"] = rel.Data
+		}
+		session.add("POST", string(src)+"/relationships", j)
+	}
+	results, err := session.flush("Neo4j.CloneNode")
+	if err != nil {
+		return tmp, err
+	}
+	if r, ok := results[0]; ok && len(r.Data) > 0 {
+		return r.Data[0], nil
+	}
+	return tmp, errors.New("Clone was not returned from neo4j.")
+}
+
+/*
+DeleteSubgraph(root node id uint, relationship types []string, max depth int) returns any errors raised as error
+walks outgoing relationships of the given types (all types when empty) from root up to max depth hops (max depth < 0 has no limit)
+then deletes every node reached along with all of their relationships in a single batch, relationships first so no delete hits a 409
+*/
+func (this *Neo4j) DeleteSubgraph(root NodeID, relTypes []string, maxDepth int) error {
+	nodes := []NodeID{root}
+	seen := map[NodeID]bool{root: true}
+	queue := []NodeID{root}
+	for depth := 0; len(queue) > 0 && (maxDepth < 0 || depth < maxDepth); depth++ {
+		next := []NodeID{}
+		for _, id := range queue {
+			rels, err := this.GetRelationshipsOfTypes(id, DirOut, relTypes...)
+			if err != nil {
+				return err
+			}
+			for i := 0; i < len(rels); i++ {
+				rel, err := rels[i].Relationship()
+				if err != nil {
+					return err
+				}
+				end := rel.EndID
+				if !seen[end] {
+					seen[end] = true
+					nodes = append(nodes, end)
+					next = append(next, end)
+				}
+			}
+		}
+		queue = next
+	}
+	session := this.NewSession()
+	deleted := map[RelID]bool{}
+	for _, id := range nodes {
+		rels, err := this.GetRelationshipsOnNode(id, "", DirAll)
+		if err != nil {
+			return err
+		}
+		for i := 0; i < len(rels); i++ {
+			rel := rels[i].RelID()
+			if !deleted[rel] { // relationships inside the subgraph show up on both ends
+				deleted[rel] = true
+				session.Delete(session.Relationship(rel))
+			}
+		}
+	}
+	for _, id := range nodes {
+		session.Delete(session.Node(id))
+	}
+	_, err := session.flush("Neo4j.DeleteSubgraph")
+	return err
+}
@@ -0,0 +1,151 @@
+package neo4j
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// state of the circuit breaker, see CircuitBreaker
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"    // requests are sent
+	BreakerOpen     BreakerState = "open"      // requests fail with ErrCircuitOpen without being sent
+	BreakerHalfOpen BreakerState = "half-open" // cooldown passed, a single request is let through to test the server
+)
+
+// returned instead of sending the request while the circuit breaker is open
+var ErrCircuitOpen = errors.New("Circuit breaker open, server considered down.")
+
+// shared by every copy of the client, see CircuitBreaker
+type breaker struct {
+	mu       sync.Mutex
+	max      int
+	cooldown time.Duration
+	onChange func(BreakerState)
+	log      func() *slog.Logger // of the client the option was applied to
+	state    BreakerState
+	failures int       // consecutive transport failures
+	opened   time.Time // when the breaker last opened
+	probing  bool      // the half-open test request is in flight
+	gen      uint64    // counts state changes, a request let through in an earlier state has no say in the current one
+}
+
+// handed out by allow for a request let through, record takes it back along with the outcome
+type breakerTicket struct {
+	gen   uint64 // breaker.gen when the request was let through
+	probe bool   // the half-open test request
+}
+
+/*
+CircuitBreaker(failures int, cooldown time.Duration, onChange func(BreakerState)) returns an Option failing requests fast once the server looks down
+after failures requests in a row couldn't reach the server, requests fail with ErrCircuitOpen for cooldown without being sent
+then one request is let through, the breaker closes again if it gets an answer and stays open for another cooldown if it doesn't
+onChange is called with every new state, pass nil when you don't need it. only transport failures count, error responses from the server don't
+*/
+func CircuitBreaker(failures int, cooldown time.Duration, onChange func(BreakerState)) Option {
+	return func(neo *Neo4j) error {
+		if failures < 1 {
+			return errors.New("Circuit breaker needs at least 1 failure to open.")
+		}
+		neo.breaker = &breaker{max: failures, cooldown: cooldown, onChange: onChange, log: neo.log, state: BreakerClosed}
+		return nil
+	}
+}
+
+/*
+BreakerState() returns the state of the circuit breaker, BreakerClosed when the client has none
+*/
+func (this *Neo4j) BreakerState() BreakerState {
+	if this.breaker == nil {
+		return BreakerClosed
+	}
+	this.breaker.mu.Lock()
+	defer this.breaker.mu.Unlock()
+	return this.breaker.state
+}
+
+// returns ErrCircuitOpen when the request may not be sent, otherwise the ticket to record its outcome with
+func (this *breaker) allow() (breakerTicket, error) {
+	if this == nil {
+		return breakerTicket{}, nil
+	}
+	this.mu.Lock()
+	switch this.state {
+	case BreakerOpen:
+		if time.Since(this.opened) < this.cooldown {
+			this.mu.Unlock()
+			return breakerTicket{}, ErrCircuitOpen
+		}
+		this.probing = true
+		ticket := breakerTicket{this.gen + 1, true} // set moves to the next generation
+		this.set(BreakerHalfOpen)
+		return ticket, nil
+	case BreakerHalfOpen:
+		if this.probing {
+			this.mu.Unlock()
+			return breakerTicket{}, ErrCircuitOpen
+		}
+		this.probing = true
+		ticket := breakerTicket{this.gen, true}
+		this.mu.Unlock()
+		return ticket, nil
+	}
+	ticket := breakerTicket{this.gen, false}
+	this.mu.Unlock()
+	return ticket, nil
+}
+
+// records the outcome of the request allow handed ticket to
+// only the probe decides a half-open breaker, requests let through before the last state change are ignored
+func (this *breaker) record(ticket breakerTicket, err error) {
+	if this == nil {
+		return
+	}
+	this.mu.Lock()
+	if ticket.probe && ticket.gen == this.gen {
+		this.probing = false
+	} else if ticket.probe || ticket.gen != this.gen || this.state != BreakerClosed {
+		this.mu.Unlock() // stale, the breaker moved on since the request was let through
+		return
+	}
+	switch {
+	case errors.Is(err, context.Canceled): // given up by the caller, says nothing about the server
+		this.mu.Unlock()
+	case err != nil:
+		this.failures++
+		if this.state == BreakerHalfOpen || this.failures >= this.max {
+			this.opened = time.Now()
+			if this.state != BreakerOpen {
+				this.set(BreakerOpen)
+				return
+			}
+		}
+		this.mu.Unlock()
+	default:
+		this.failures = 0
+		if this.state != BreakerClosed {
+			this.set(BreakerClosed)
+			return
+		}
+		this.mu.Unlock()
+	}
+}
+
+// changes the state and unlocks, onChange runs after unlocking so it may call back into the client
+func (this *breaker) set(state BreakerState) {
+	this.state = state
+	this.gen++
+	this.mu.Unlock()
+	if state == BreakerOpen {
+		this.log().Warn("neo4j: circuit breaker open, failing requests fast", "cooldown", this.cooldown)
+	} else {
+		this.log().Info("neo4j: circuit breaker " + string(state))
+	}
+	if this.onChange != nil {
+		this.onChange(state)
+	}
+}
@@ -0,0 +1,111 @@
+package neo4j
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// server answering node requests, or cutting the connection without an answer while down
+type flakyServer struct {
+	down     atomic.Bool
+	received atomic.Int32
+}
+
+func (this *flakyServer) serve(w http.ResponseWriter, r *http.Request) {
+	this.received.Add(1)
+	if this.down.Load() {
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err == nil {
+			conn.Close()
+		}
+		return
+	}
+	fmt.Fprint(w, `{"self": "http://localhost/db/data/node/1", "data": {}}`)
+}
+
+// closed -> open after 2 failures -> failing fast -> half-open after the cooldown -> open again on a failed probe -> closed on a good one
+func TestCircuitBreaker(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		states []BreakerState
+	)
+	srv := new(flakyServer)
+	neo := newStubClient(t, srv.serve, CircuitBreaker(2, 20*time.Millisecond, func(state BreakerState) {
+		mu.Lock()
+		states = append(states, state)
+		mu.Unlock()
+	}))
+	srv.down.Store(true)
+	for i := 0; i < 2; i++ {
+		_, err := neo.GetNode(1)
+		if err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("request %d: got %v, want the connection error", i+1, err)
+		}
+	}
+	if neo.BreakerState() != BreakerOpen {
+		t.Fatalf("got %s after 2 failures, want open", neo.BreakerState())
+	}
+	received := srv.received.Load()
+	_, err := neo.GetNode(1)
+	if !errors.Is(err, ErrCircuitOpen) || srv.received.Load() != received {
+		t.Fatalf("got %v and the request sent, want ErrCircuitOpen without sending it", err)
+	}
+	time.Sleep(30 * time.Millisecond)
+	_, err = neo.GetNode(1) // the probe, still down
+	if err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("probe: got %v, want the connection error", err)
+	}
+	if neo.BreakerState() != BreakerOpen {
+		t.Fatalf("got %s after a failed probe, want open", neo.BreakerState())
+	}
+	srv.down.Store(false)
+	time.Sleep(30 * time.Millisecond)
+	_, err = neo.GetNode(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if neo.BreakerState() != BreakerClosed {
+		t.Fatalf("got %s after a good probe, want closed", neo.BreakerState())
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	want := []BreakerState{BreakerOpen, BreakerHalfOpen, BreakerOpen, BreakerHalfOpen, BreakerClosed}
+	if fmt.Sprint(states) != fmt.Sprint(want) {
+		t.Errorf("got states %v, want %v", states, want)
+	}
+}
+
+// requests let through before the breaker opened must not decide the half-open state, only the probe does
+func TestBreakerStaleOutcome(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	b := &breaker{max: 1, log: func() *slog.Logger { return logger }, state: BreakerClosed}
+	early, _ := b.allow() // still in flight when the breaker opens
+	failed, _ := b.allow()
+	b.record(failed, io.ErrUnexpectedEOF)
+	if b.state != BreakerOpen {
+		t.Fatalf("got %s, want open", b.state)
+	}
+	probe, err := b.allow() // no cooldown
+	if err != nil || b.state != BreakerHalfOpen {
+		t.Fatalf("got %v in state %s, want the probe let through", err, b.state)
+	}
+	b.record(early, nil)
+	if b.state != BreakerHalfOpen {
+		t.Fatalf("a request from before the breaker opened moved it to %s", b.state)
+	}
+	_, err = b.allow()
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("got %v, want a second probe refused", err)
+	}
+	b.record(probe, nil)
+	if b.state != BreakerClosed {
+		t.Fatalf("got %s after the probe succeeded, want closed", b.state)
+	}
+}
@@ -0,0 +1,60 @@
+package neo4j
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+)
+
+// buffers bigger than this aren't put back so one huge response doesn't stay pinned in the pool
+const maxPooledBuffer = 1 << 20
+
+// reused for request payloads and response bodies so busy loaders don't allocate a buffer per request
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+// takes an empty buffer from the pool, hand it back with putBuffer once nothing refers to its bytes anymore
+func getBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBuffer {
+		return
+	}
+	buf.Reset()
+	bufferPool.Put(buf)
+}
+
+// a pooled buffer with an encoder writing into it, see marshal
+type encodeBuffer struct {
+	bytes.Buffer
+	enc *json.Encoder
+}
+
+var encoderPool = sync.Pool{
+	New: func() interface{} {
+		buf := new(encodeBuffer)
+		buf.enc = json.NewEncoder(&buf.Buffer)
+		return buf
+	},
+}
+
+// same as json.Marshal but encodes into a pooled buffer with a pooled encoder, the string returned is the only allocation of its own
+func marshal(v interface{}) (string, error) {
+	buf := encoderPool.Get().(*encodeBuffer)
+	defer func() {
+		if buf.Cap() <= maxPooledBuffer {
+			buf.Reset()
+			encoderPool.Put(buf)
+		}
+	}()
+	err := buf.enc.Encode(v)
+	if err != nil {
+		return "", err
+	}
+	return string(bytes.TrimSuffix(buf.Bytes(), []byte("\n"))), nil // Encode ends every value with a newline, Marshal doesn't
+}
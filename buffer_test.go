@@ -0,0 +1,87 @@
+package neo4j
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// a node with enough properties that the body takes several reads
+func benchmarkNode() map[string]interface{} {
+	data := map[string]interface{}{}
+	for i := 0; i < 64; i++ {
+		data[fmt.Sprintf("property_%d", i)] = strings.Repeat("value ", 8)
+	}
+	return data
+}
+
+// client of a server answering every request with body
+func benchmarkClient(b *testing.B, body string) *Neo4j {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		io.WriteString(w, body)
+	}))
+	b.Cleanup(srv.Close)
+	neo := &Neo4j{URL: srv.URL, Client: newHTTPClient()}
+	return neo
+}
+
+func BenchmarkMarshalPooled(b *testing.B) {
+	data := benchmarkNode()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, err := marshal(data)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMarshalUnpooled(b *testing.B) {
+	data := benchmarkNode()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		s, err := json.Marshal(data)
+		if err != nil {
+			b.Fatal(err)
+		}
+		_ = string(s)
+	}
+}
+
+func BenchmarkSendPooled(b *testing.B) {
+	body, _ := json.Marshal(map[string]interface{}{"data": benchmarkNode()})
+	neo := benchmarkClient(b, string(body))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _, err := neo.send("Neo4j.GetNode", "GET", neo.URL+"/node/1", "")
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// what send did before the pool: read the whole body into a fresh slice
+func BenchmarkSendUnpooled(b *testing.B) {
+	body, _ := json.Marshal(map[string]interface{}{"data": benchmarkNode()})
+	neo := benchmarkClient(b, string(body))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		resp, err := neo.do("Neo4j.GetNode", "GET", neo.URL+"/node/1", "")
+		if err != nil {
+			b.Fatal(err)
+		}
+		data, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			b.Fatal(err)
+		}
+		_ = string(data)
+	}
+}
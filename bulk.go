@@ -0,0 +1,180 @@
+package neo4j
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// runs bulk node/relationship/property operations using a bounded number of goroutines
+type Bulk struct {
+	Workers int // max number of requests in flight at once
+	neo     *Neo4j
+}
+
+// aggregates the errors raised by the individual items of a bulk operation, keyed by item position
+type MultiError struct {
+	List map[int]error
+}
+
+func (this *MultiError) Error() string {
+	keys := make([]int, 0, len(this.List))
+	for k := range this.List {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	msgs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		msgs = append(msgs, "item "+strconv.Itoa(k)+": "+this.List[k].Error())
+	}
+	return strconv.Itoa(len(keys)) + " of the bulk operations failed: " + strings.Join(msgs, "; ")
+}
+
+// makes errors.Is and errors.As look at the error of every failed item
+func (this *MultiError) Unwrap() []error {
+	list := make([]error, 0, len(this.List))
+	for _, err := range this.List {
+		list = append(list, err)
+	}
+	return list
+}
+
+/*
+NewBulk(workers int) returns a Bulk executor bound to this client
+workers below 1 defaults to a single goroutine
+*/
+func (this *Neo4j) NewBulk(workers int) *Bulk {
+	if workers < 1 {
+		workers = 1
+	}
+	return &Bulk{Workers: workers, neo: this}
+}
+
+/*
+Run(jobs ...func(*Neo4j) error) returns any errors raised as *MultiError
+jobs run on several goroutines at once sharing the client, which is safe for concurrent use
+*/
+func (this *Bulk) Run(jobs ...func(*Neo4j) error) error {
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs = map[int]error{}
+	)
+	if this.neo == nil {
+		return errors.New("Bulk executor has no client, use Neo4j.NewBulk.")
+	}
+	workers := this.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	queue := make(chan int)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				err := jobs[i](this.neo)
+				if err != nil {
+					mu.Lock()
+					errs[i] = err
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for i := range jobs {
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+	if len(errs) > 0 {
+		return &MultiError{errs}
+	}
+	return nil
+}
+
+/*
+CreateNodes(data ...map[string]string) returns a slice of NeoTemplate structs in the order of data and any errors raised as *MultiError
+nodes that failed to be created are left nil
+*/
+func (this *Bulk) CreateNodes(data ...map[string]string) ([]*NeoTemplate, error) {
+	var mu sync.Mutex
+	dataSet := make([]*NeoTemplate, len(data))
+	jobs := make([]func(*Neo4j) error, len(data))
+	for i, d := range data {
+		i, d := i, d
+		jobs[i] = func(neo *Neo4j) error {
+			node, err := neo.CreateNode(d)
+			if err != nil {
+				return err
+			}
+			mu.Lock()
+			dataSet[i] = node
+			mu.Unlock()
+			return nil
+		}
+	}
+	return dataSet, this.Run(jobs...)
+}
+
+/*
+DelNodes(id ...NodeID) returns any errors raised as *MultiError
+*/
+func (this *Bulk) DelNodes(id ...NodeID) error {
+	jobs := make([]func(*Neo4j) error, len(id))
+	for i, n := range id {
+		n := n
+		jobs[i] = func(neo *Neo4j) error {
+			return neo.DelNode(n)
+		}
+	}
+	return this.Run(jobs...)
+}
+
+/*
+DelRelationships(id ...RelID) returns any errors raised as *MultiError
+*/
+func (this *Bulk) DelRelationships(id ...RelID) error {
+	jobs := make([]func(*Neo4j) error, len(id))
+	for i, r := range id {
+		r := r
+		jobs[i] = func(neo *Neo4j) error {
+			return neo.DelRelationship(r)
+		}
+	}
+	return this.Run(jobs...)
+}
+
+/*
+SetProperties(data map[NodeID]map[string]string, replace bool) returns any errors raised as *MultiError
+data is keyed by node id, errors are keyed by the node id as well
+*/
+func (this *Bulk) SetProperties(data map[NodeID]map[string]string, replace bool) error {
+	ids := make([]NodeID, 0, len(data))
+	for id := range data {
+		ids = append(ids, id)
+	}
+	jobs := make([]func(*Neo4j) error, len(ids))
+	for i, id := range ids {
+		id := id
+		jobs[i] = func(neo *Neo4j) error {
+			return neo.SetProperty(id, data[id], replace)
+		}
+	}
+	err := this.Run(jobs...)
+	if err != nil {
+		// re-key the errors by node id so the caller can tell which node failed
+		multi, ok := err.(*MultiError)
+		if !ok {
+			return err
+		}
+		byID := map[int]error{}
+		for i, e := range multi.List {
+			byID[int(ids[i])] = e
+		}
+		return &MultiError{byID}
+	}
+	return nil
+}
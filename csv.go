@@ -0,0 +1,172 @@
+package neo4j
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// describes how the columns of a csv file map onto the graph
+type CSVMapping struct {
+	Columns      map[string]string // csv header -> property name. columns not listed are skipped, a nil map imports every column as is
+	KeyColumn    string            // optional column identifying each node; ImportResult.Nodes maps its values to the new node ids
+	Relationship *CSVRelationship  // when set every row creates a relationship instead of a node
+}
+
+// maps a csv row onto a relationship
+type CSVRelationship struct {
+	Start      string            // column holding the start node
+	End        string            // column holding the end node
+	Type       string            // relationship type
+	TypeColumn string            // column holding the relationship type, overrides Type
+	Keys       map[string]NodeID // resolves Start/End values to node ids (ie: ImportResult.Nodes of an earlier import). when nil the values must be node ids
+}
+
+// tweaks how ImportCSV reads the file and talks to neo4j
+type ImportOptions struct {
+	BatchSize int  // rows per batch request, defaults to 500
+	Comma     rune // field delimiter, defaults to ','
+}
+
+// what ImportCSV did
+type ImportResult struct {
+	Rows          int               // rows imported
+	Nodes         map[string]NodeID // value of CSVMapping.KeyColumn -> node id
+	Relationships []RelID           // ids of the created relationships in row order
+}
+
+/*
+ImportCSV(r io.Reader, mapping *CSVMapping, opts *ImportOptions) returns an ImportResult struct and any errors raised as error
+the first row must hold the column headers. rows are streamed from r and written in batches of opts.BatchSize
+each batch runs in its own transaction, on error the ImportResult covers the batches written so far
+*/
+func (this *Neo4j) ImportCSV(r io.Reader, mapping *CSVMapping, opts *ImportOptions) (*ImportResult, error) {
+	if mapping == nil {
+		mapping = &CSVMapping{}
+	}
+	size := 500
+	reader := csv.NewReader(r)
+	if opts != nil {
+		if opts.BatchSize > 0 {
+			size = opts.BatchSize
+		}
+		if opts.Comma != 0 {
+			reader.Comma = opts.Comma
+		}
+	}
+	result := &ImportResult{Nodes: map[string]NodeID{}}
+	header, err := reader.Read()
+	if err != nil {
+		return result, err
+	}
+	cols := map[string]int{} // header -> column position
+	for i, h := range header {
+		cols[strings.TrimSpace(h)] = i
+	}
+	required := []string{}
+	if len(mapping.KeyColumn) > 0 {
+		required = append(required, mapping.KeyColumn)
+	}
+	if rel := mapping.Relationship; rel != nil {
+		required = append(required, rel.Start, rel.End)
+		if len(rel.TypeColumn) > 0 {
+			required = append(required, rel.TypeColumn)
+		}
+	}
+	for _, c := range required {
+		if _, ok := cols[c]; !ok {
+			return result, errors.New("Column " + c + " not found in csv header.")
+		}
+	}
+	session := this.NewSession()
+	keys := []string{} // KeyColumn value per pending node in session order
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return result, err
+		}
+		props := map[string]string{}
+		for h, i := range cols {
+			name := h
+			if mapping.Columns != nil {
+				n, ok := mapping.Columns[h]
+				if !ok {
+					continue
+				}
+				name = n
+			}
+			if i < len(row) {
+				props[name] = row[i]
+			}
+		}
+		if rel := mapping.Relationship; rel != nil {
+			start, err := rel.node(row[cols[rel.Start]])
+			if err != nil {
+				return result, err
+			}
+			end, err := rel.node(row[cols[rel.End]])
+			if err != nil {
+				return result, err
+			}
+			rType := rel.Type
+			if len(rel.TypeColumn) > 0 {
+				rType = row[cols[rel.TypeColumn]]
+			}
+			session.CreateRelationship(session.Node(start), session.Node(end), props, rType)
+		} else {
+			session.CreateNode(props)
+			if len(mapping.KeyColumn) > 0 {
+				keys = append(keys, row[cols[mapping.KeyColumn]])
+			}
+		}
+		if session.Len() >= size {
+			err = this.flushImport("Neo4j.ImportCSV", session, result, keys)
+			if err != nil {
+				return result, err
+			}
+			keys = keys[:0]
+		}
+	}
+	return result, this.flushImport("Neo4j.ImportCSV", session, result, keys)
+}
+
+// resolves a start/end column value into a node id
+func (this *CSVRelationship) node(v string) (NodeID, error) {
+	v = strings.TrimSpace(v)
+	if this.Keys != nil {
+		id, ok := this.Keys[v]
+		if !ok {
+			return 0, errors.New("No node found for key " + v + ".")
+		}
+		return id, nil
+	}
+	id, err := strconv.ParseUint(v, 10, 64)
+	return NodeID(id), err
+}
+
+// sends the pending rows and records what was created on result
+func (this *Neo4j) flushImport(op string, session *Session, result *ImportResult, keys []string) error {
+	rows := session.Len()
+	results, err := session.flush(op)
+	if err != nil {
+		return err
+	}
+	result.Rows += rows
+	for i := 0; i < rows; i++ {
+		r, ok := results[i]
+		if !ok || len(r.Data) < 1 {
+			continue
+		}
+		if len(keys) > 0 {
+			result.Nodes[keys[i]] = r.Data[0].NodeID()
+		} else if len(r.Data[0].Type) > 0 {
+			result.Relationships = append(result.Relationships, r.Data[0].RelID())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,437 @@
+package neo4j
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// result of a cypher query: one slice of values per row, in the order of Columns
+type CypherResult struct {
+	Columns []string        `json:"columns"`
+	Data    [][]interface{} `json:"data"`
+}
+
+/*
+Cypher(query string, params map[string]interface{}) returns a CypherResult struct and any errors raised as error
+params are referenced from the query as {name}, pass nil when there are none
+*/
+func (this *Neo4j) Cypher(query string, params map[string]interface{}) (*CypherResult, error) {
+	return this.cypher("Neo4j.Cypher", query, params)
+}
+
+// sends the query for Cypher, op is the client method it is sent for
+func (this *Neo4j) cypher(op string, query string, params map[string]interface{}) (*CypherResult, error) {
+	if len(query) < 1 {
+		return nil, errors.New("Query must be at least 1 character.")
+	}
+	if params == nil {
+		params = map[string]interface{}{}
+	}
+	s, err := marshal(map[string]interface{}{"query": query, "params": params})
+	if err != nil {
+		return nil, errors.New("Unable to Marshal Json data")
+	}
+	errorList := map[int]error{
+		400: errors.New("Invalid cypher query."),
+	}
+	result := new(CypherResult)
+	err = this.receive(op, "POST", this.endpoint("cypher", "/cypher"), s, errorList, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// converts a node or relationship returned in a cypher column into a NeoTemplate struct
+func (this *Neo4j) cypherTemplate(v interface{}) (*NeoTemplate, error) {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("Cypher column does not hold a node or relationship.")
+	}
+	return this.unmarshalNode(m)
+}
+
+/*
+CreateUniqueRelationship(src node id uint, dst node id uint, data map[string]string, relationship type string) returns a Relationship struct, whether it was created and any errors raised as error
+only creates the relationship when there is no relationship of the same type from src to dst yet, otherwise the existing one is returned untouched
+uses a cypher MERGE so concurrent callers can't create duplicates, created is set by its ON CREATE so it holds under concurrent callers as well
+data is encoded like the properties of CreateRelationshipTyped and checked by the global validators, see RegisterValidator
+*/
+func (this *Neo4j) CreateUniqueRelationship(src NodeID, dst NodeID, data map[string]string, rType string) (rel *Relationship, created bool, err error) {
+	if len(rType) < 1 {
+		return nil, false, errors.New("Relationship type must be at least 1 character.")
+	}
+	props := map[string]interface{}{}
+	if data != nil {
+		props = typedProperties(data)
+	}
+	err = this.validate(props)
+	if err != nil {
+		return nil, false, err
+	}
+	props, err = this.encodeProperties(props)
+	if err != nil {
+		return nil, false, err
+	}
+	query := "MATCH (a), (b) WHERE id(a) = {src} AND id(b) = {dst} " +
+		"MERGE (a)-[r:" + this.cypherName(rType) + "]->(b) ON CREATE SET r = {props}, r._created_ = true " +
+		"WITH r, coalesce(r._created_, false) AS created REMOVE r._created_ " +
+		"RETURN r, created"
+	params := map[string]interface{}{"src": src, "dst": dst, "props": props}
+	result, err := this.cypher("Neo4j.CreateUniqueRelationship", query, params)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(result.Data) < 1 || len(result.Data[0]) < 2 {
+		return nil, false, errors.New("Node or 'to' node not found.")
+	}
+	template, err := this.cypherTemplate(result.Data[0][0])
+	if err != nil {
+		return nil, false, err
+	}
+	rel, err = template.Relationship()
+	if err != nil {
+		return nil, false, err
+	}
+	created, _ = result.Data[0][1].(bool)
+	return rel, created, nil
+}
+
+/*
+MergeNode(label string, match map[string]interface{}, set map[string]interface{}) returns a NeoTemplate struct of the node and any errors raised as error
+finds the node carrying label whose properties equal match, creating it when there is none, then sets the properties in set on it
+runs as a single cypher MERGE so the create if absent / update if present is atomic
+*/
+func (this *Neo4j) MergeNode(label string, match map[string]interface{}, set map[string]interface{}) (tmp *NeoTemplate, err error) {
+	if len(label) < 1 {
+		return tmp, errors.New("Label must be at least 1 character.")
+	}
+	merged := map[string]interface{}{}
+	for k, v := range match {
+		merged[k] = v
+	}
+	for k, v := range set {
+		merged[k] = v
+	}
+	err = this.validate(merged)
+	if err != nil {
+		return tmp, err
+	}
+	err = this.validateLabels([]string{label}, merged)
+	if err != nil {
+		return tmp, err
+	}
+	match, err = this.encodeProperties(match)
+	if err != nil {
+		return tmp, err
+	}
+	set, err = this.encodeProperties(set)
+	if err != nil {
+		return tmp, err
+	}
+	params := map[string]interface{}{}
+	props := []string{}
+	for i, k := range this.sortedKeys(match) {
+		p := "m" + strconv.Itoa(i)
+		params[p] = match[k]
+		props = append(props, this.cypherName(k)+": {"+p+"}")
+	}
+	query := "MERGE (n:" + this.cypherName(label)
+	if len(props) > 0 {
+		query += " {" + strings.Join(props, ", ") + "}"
+	}
+	query += ")"
+	_, matched := match[this.UUIDProperty]
+	_, setting := set[this.UUIDProperty]
+	if len(this.UUIDProperty) > 0 && !matched && !setting { // only a node the merge creates gets one, see UseUUIDs
+		params["uuid"], err = newUUID()
+		if err != nil {
+			return tmp, err
+		}
+		query += " ON CREATE SET n." + this.cypherName(this.UUIDProperty) + " = {uuid}"
+	}
+	sets := []string{}
+	for i, k := range this.sortedKeys(set) {
+		p := "s" + strconv.Itoa(i)
+		params[p] = set[k]
+		sets = append(sets, "n."+this.cypherName(k)+" = {"+p+"}")
+	}
+	if len(sets) > 0 {
+		query += " SET " + strings.Join(sets, ", ")
+	}
+	query += " RETURN n"
+	result, err := this.cypher("Neo4j.MergeNode", query, params)
+	if err != nil {
+		return tmp, err
+	}
+	if len(result.Data) < 1 || len(result.Data[0]) < 1 {
+		return tmp, errors.New("Merge returned no node.")
+	}
+	return this.cypherTemplate(result.Data[0][0])
+}
+
+// pages through every node in the database, see AllNodes
+type NodeIterator struct {
+	neo  *Neo4j
+	size int
+	last int64          // id of the last node fetched, pages are keyed on it so concurrent writes don't shift them
+	page []*NeoTemplate // rest of the current page
+	node *NeoTemplate
+	err  error
+	done bool
+}
+
+/*
+AllNodes(page size int) returns a NodeIterator over every node in the database ordered by id
+nodes are fetched page size at a time (default 1000) as Next() needs them:
+
+	it := neo.AllNodes(0)
+	for it.Next() {
+		node := it.Node()
+	}
+	if it.Err() != nil { ... }
+*/
+func (this *Neo4j) AllNodes(size int) *NodeIterator {
+	if size < 1 {
+		size = 1000
+	}
+	return &NodeIterator{neo: this, size: size, last: -1}
+}
+
+/*
+Next() returns whether there is another node, fetching the next page when needed
+*/
+func (this *NodeIterator) Next() bool {
+	if this.err != nil {
+		return false
+	}
+	if len(this.page) < 1 && !this.done {
+		this.err = this.fetch("NodeIterator.Next")
+		if this.err != nil {
+			return false
+		}
+	}
+	if len(this.page) < 1 {
+		this.node = nil
+		return false
+	}
+	this.node = this.page[0]
+	this.page = this.page[1:]
+	return true
+}
+
+/*
+Node() returns the current node
+*/
+func (this *NodeIterator) Node() *NeoTemplate {
+	return this.node
+}
+
+/*
+Err() returns the error that stopped the iteration, if any
+*/
+func (this *NodeIterator) Err() error {
+	return this.err
+}
+
+// loads the next page of nodes
+func (this *NodeIterator) fetch(op string) error {
+	params := map[string]interface{}{"last": this.last, "size": this.size}
+	result, err := this.neo.cypher(op, "MATCH (n) WHERE id(n) > {last} RETURN n ORDER BY id(n) LIMIT {size}", params)
+	if err != nil {
+		return err
+	}
+	for _, row := range result.Data {
+		if len(row) < 1 {
+			continue
+		}
+		node, err := this.neo.cypherTemplate(row[0])
+		if err != nil {
+			return err
+		}
+		this.page = append(this.page, node)
+		this.last = int64(node.ID)
+	}
+	if len(result.Data) < this.size {
+		this.done = true
+	}
+	return nil
+}
+
+/*
+IncrementProperty(node id uint, key string, delta int64) returns the new value of the property and any errors raised as error
+adds delta to the property in a single cypher statement, a missing property counts as 0
+the node is write locked before the property is read so concurrent increments never lose updates
+validators don't run, the new value is only known on the server. see RegisterValidator
+*/
+func (this *Neo4j) IncrementProperty(id NodeID, key string, delta int64) (int64, error) {
+	key = strings.TrimSpace(key)
+	if len(key) < 1 {
+		return 0, errors.New("Property name must be at least 1 character.")
+	}
+	prop := "n." + this.cypherName(key)
+	query := "START n=node({id}) SET n.`_lock_` = true " + // taking the write lock up front
+		"SET " + prop + " = coalesce(" + prop + ", 0) + {delta} " +
+		"REMOVE n.`_lock_` RETURN " + prop
+	result, err := this.cypher("Neo4j.IncrementProperty", query, map[string]interface{}{"id": id, "delta": delta})
+	if err != nil {
+		return 0, err
+	}
+	if len(result.Data) < 1 || len(result.Data[0]) < 1 {
+		return 0, errors.New("Node not found.")
+	}
+	f, ok := result.Data[0][0].(float64)
+	if !ok {
+		return 0, errors.New("Property " + key + " is not a number.")
+	}
+	return int64(f), nil
+}
+
+// sets and removes node properties in a single cypher statement
+func (this *Neo4j) setAndRemove(op string, id NodeID, set map[string]interface{}, remove []string) error {
+	params := map[string]interface{}{"id": id}
+	query := "START n=node({id})"
+	sets := []string{}
+	for i, k := range this.sortedKeys(set) {
+		p := "s" + strconv.Itoa(i)
+		params[p] = set[k]
+		sets = append(sets, "n."+this.cypherName(strings.TrimSpace(k))+" = {"+p+"}")
+	}
+	if len(sets) > 0 {
+		query += " SET " + strings.Join(sets, ", ")
+	}
+	removes := make([]string, len(remove))
+	for i, k := range remove {
+		removes[i] = "n." + this.cypherName(k)
+	}
+	if len(removes) > 0 {
+		query += " REMOVE " + strings.Join(removes, ", ")
+	}
+	_, err := this.cypher(op, query, params)
+	return err
+}
+
+// steps through the rows of a cypher result the way database/sql.Rows does, see Query
+type Rows struct {
+	neo    *Neo4j
+	result *CypherResult
+	row    int // index of the current row plus one, 0 before the first Next
+}
+
+/*
+Query(query string, params map[string]interface{}) returns Rows over the result and any errors raised as error
+see Cypher for params:
+
+	rows, err := neo.Query("MATCH (p:Person) RETURN p, p.age", nil)
+	for rows.Next() {
+		var p Person
+		var age int
+		err = rows.Scan(&p, &age)
+	}
+*/
+func (this *Neo4j) Query(query string, params map[string]interface{}) (*Rows, error) {
+	result, err := this.cypher("Neo4j.Query", query, params)
+	if err != nil {
+		return nil, err
+	}
+	return result.Rows(this), nil
+}
+
+/*
+Rows(neo *Neo4j) returns Rows over the result, neo is used to convert values in Scan
+*/
+func (this *CypherResult) Rows(neo *Neo4j) *Rows {
+	return &Rows{neo: neo, result: this}
+}
+
+/*
+Columns() returns the column names of the result
+*/
+func (this *Rows) Columns() []string {
+	return this.result.Columns
+}
+
+/*
+Next() returns whether there is another row and moves to it
+*/
+func (this *Rows) Next() bool {
+	if this.row >= len(this.result.Data) {
+		return false
+	}
+	this.row++
+	return true
+}
+
+/*
+Scan(dest ...interface{}) returns any errors raised as error
+copies the columns of the current row into the values dest points to, one per column
+nodes and relationships scan into *NeoTemplate, relationships into *Relationship as well and nodes into mapped structs (see Unmarshal)
+*interface{} takes the value as is, anything else is converted with FromNeo
+*/
+func (this *Rows) Scan(dest ...interface{}) error {
+	if this.row < 1 {
+		return errors.New("Scan called without calling Next.")
+	}
+	row := this.result.Data[this.row-1]
+	if len(dest) != len(row) {
+		return errors.New("Expected " + strconv.Itoa(len(row)) + " destinations, got " + strconv.Itoa(len(dest)) + ".")
+	}
+	for i, d := range dest {
+		err := this.scanValue(row[i], d)
+		if err != nil {
+			return errors.New("Column " + this.result.Columns[i] + ": " + err.Error())
+		}
+	}
+	return nil
+}
+
+// converts one column value into what dest points to
+func (this *Rows) scanValue(v interface{}, dest interface{}) error {
+	switch d := dest.(type) {
+	case *interface{}:
+		*d = v
+		return nil
+	case *NeoTemplate:
+		tmpl, err := this.neo.cypherTemplate(v)
+		if err != nil {
+			return err
+		}
+		*d = *tmpl
+		return nil
+	case **NeoTemplate:
+		tmpl, err := this.neo.cypherTemplate(v)
+		if err != nil {
+			return err
+		}
+		*d = tmpl
+		return nil
+	case *Relationship, **Relationship:
+		tmpl, err := this.neo.cypherTemplate(v)
+		if err != nil {
+			return err
+		}
+		rel, err := tmpl.Relationship()
+		if err != nil {
+			return err
+		}
+		if p, ok := d.(*Relationship); ok {
+			*p = *rel
+		} else {
+			*d.(**Relationship) = rel
+		}
+		return nil
+	}
+	if _, isMap := v.(map[string]interface{}); isMap {
+		ptr := reflect.ValueOf(dest)
+		if ptr.Kind() == reflect.Ptr && !ptr.IsNil() && ptr.Elem().Kind() == reflect.Struct {
+			tmpl, err := this.neo.cypherTemplate(v)
+			if err != nil {
+				return err
+			}
+			return this.neo.Unmarshal(tmpl, dest)
+		}
+	}
+	return this.neo.FromNeo(v, dest)
+}
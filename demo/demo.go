@@ -18,7 +18,7 @@ func main() {
 
 	data, _ := neo.CreateNode(node)
 	log.Printf("\nNode ID: %v\n", data.ID)
-	self := data.ID
+	self := data.NodeID()
 
 	data, _ = neo.GetNode(self)
 	log.Printf("\nNode data: %v\n", data)
@@ -79,7 +79,7 @@ func main() {
 		"test": "true",
 	}
 	/* node id(uint), to node id(uint), data(map[string]string), type */
-	err = neo.CreateRelationship(self, (self - 1), ndata, "KNOWS")
+	_, err = neo.CreateRelationship(self, (self - 1), ndata, "KNOWS")
 	if err != nil {
 		log.Printf("Create Relationship failed with error: %v\n", err)
 	} else {
@@ -87,7 +87,7 @@ func main() {
 	}
 
 	/* node id(uint), to node id(uint), data(map[string]string), type */
-	err = neo.CreateRelationship(self, (self - 2), ndata, "KNOWS")
+	_, err = neo.CreateRelationship(self, (self - 2), ndata, "KNOWS")
 	if err != nil {
 		log.Printf("Create Relationship failed with error: %v\n", err)
 	} else {
@@ -99,7 +99,7 @@ func main() {
 		"test": "false",
 	}
 	/* idx key(string), idx value(string), idx category(string), idx type[node|relationship](string) */
-	err = neo.CreateIdx((self - 1), "a_test", "testing1", "idx_type", "node")
+	err = neo.CreateNodeIdx(self-1, "a_test", "testing1", "idx_type")
 	if err != nil {
 		log.Printf("CreateIdx failed with error: %v\n", err)
 	} else {
@@ -107,7 +107,7 @@ func main() {
 	}
 
 	/* idx key(string), idx value(string), idx category(string), idx type[node|relationship](string) */
-	err = neo.CreateIdx((self - 2), "a_test", "testing2", "idx_type", "node")
+	err = neo.CreateNodeIdx(self-2, "a_test", "testing2", "idx_type")
 	if err != nil {
 		log.Printf("CreateIdx failed with error: %v\n", err)
 	} else {
@@ -169,9 +169,9 @@ func main() {
 	}
 	/*
 		Traverse(id uint, returnType string, order string, uniqueness string, relationships map[string]string, depth int, prune map[string]string, filter map[string]string)
-		some possible values: uniqueness:[node|node path],  filter names:[all|all but start node] 
+		some possible values: uniqueness:[node global|node path],  filter names:[all|all but start node] 
 	*/
-	dataSet, err = neo.Traverse(self, "node", "depth first", "node", nil, 2, nil, filter) //
+	dataSet, err = neo.Traverse(self, "node", "depth first", "node global", nil, 2, nil, filter) //
 	if err != nil {
 		log.Printf("Traverse failed with error: %v\n", err)
 	} else {
@@ -181,18 +181,18 @@ func main() {
 	}
 
 	// set & delete relationships on node
-	dataSet, err = neo.GetRelationshipsOnNode(self, "KNOWS", "all") // id(uint), type string, direction string
+	dataSet, err = neo.GetRelationshipsOnNode(self, "KNOWS", neo4j.DirAll) // id(uint), type string, direction Direction
 	if err != nil {
 		log.Printf("GetRelationshipsOnNode error: %v\n", err)
 	} else {
 		for _, v := range dataSet { // loop the dataSet returned and print array key(int) and relationship ID
-			err = neo.SetRelationship(v.ID, rdata) // relationship id uint, map[string]string 
+			err = neo.SetRelationship(v.RelID(), rdata) // relationship id uint, map[string]string 
 			if err != nil {
 				log.Printf("Set relationship failed with error: %v\n", err)
 			} else {
 				log.Printf("Relationship properties updated.\n")
 			}
-			err = neo.DelRelationship(v.ID) // id ...uint  --relationship ids
+			err = neo.DelRelationship(v.RelID()) // id ...uint  --relationship ids
 			if err != nil {
 				log.Printf("Del Relationship failed with error: %v\n", err)
 			} else {
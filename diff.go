@@ -0,0 +1,398 @@
+package neo4j
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// kinds of DiffOp
+const (
+	DiffCreateNode         = "create_node"
+	DiffSetProperty        = "set_property"
+	DiffRemoveProperty     = "remove_property"
+	DiffCreateRelationship = "create_relationship"
+	DiffDelRelationship    = "delete_relationship"
+)
+
+// a single change found by Diff
+type DiffOp struct {
+	Kind  string      // one of the Diff* constants
+	Ref   Ref         // node the change applies to, the relationship for DiffDelRelationship
+	Name  string      // property name or relationship type
+	Value interface{} // new property value, the Ref of the other node for DiffCreateRelationship
+}
+
+// changes needed to bring the stored graph in line with an object graph, see Diff
+type GraphDiff struct {
+	Ops     []*DiffOp
+	session *Session
+	after   []func(results map[int]*BatchResult) error // copy what the batch created back into the entities
+}
+
+// an entity visited by Diff
+type diffEntity struct {
+	ref Ref
+	id  NodeID
+	new bool
+}
+
+/*
+Diff(entity interface{}) returns the GraphDiff between entity with every entity reachable through its relationship fields and the stored graph, and any errors raised as error
+entities with a zero id are created, properties that changed are set, ones whose field became empty (see omitempty) removed,
+missing relationships are created and stored relationships of a mapped type leading to nodes no longer referenced are deleted
+nodes themselves are never deleted and properties or relationship types not mapped by the entities are left alone
+nothing is written until Apply(), see Save for the mapping and the version check
+*/
+func (this *Neo4j) Diff(entity interface{}) (*GraphDiff, error) {
+	ptr := reflect.ValueOf(entity)
+	if ptr.Kind() != reflect.Ptr || ptr.IsNil() || ptr.Elem().Kind() != reflect.Struct {
+		return nil, errors.New("Entity must be a non nil pointer to a struct.")
+	}
+	diff := &GraphDiff{session: this.NewSession()}
+	_, err := this.diff(diff, ptr, map[uintptr]*diffEntity{})
+	if err != nil {
+		return nil, err
+	}
+	return diff, nil
+}
+
+// adds the changes of the entity ptr points to and of its relationships to diff, seen holds the entities visited already so cycles end
+func (this *Neo4j) diff(diff *GraphDiff, ptr reflect.Value, seen map[uintptr]*diffEntity) (*diffEntity, error) {
+	if e, ok := seen[ptr.Pointer()]; ok {
+		return e, nil
+	}
+	v := ptr.Elem()
+	fields := entityFields(v.Type())
+	var idField, versionField *entityField
+	for _, f := range fields {
+		if f.ID {
+			idField = f
+		}
+		if f.Version {
+			versionField = f
+		}
+	}
+	if idField == nil {
+		return nil, errors.New("Entity " + v.Type().Name() + " has no field tagged neo4j:\",id\".")
+	}
+	label, props, err := this.Marshal(ptr.Interface())
+	if err != nil {
+		return nil, err
+	}
+	idValue := v.FieldByIndex(idField.Index)
+	e := &diffEntity{new: idValue.IsZero()}
+	seen[ptr.Pointer()] = e
+	if e.new {
+		err = this.diffCreate(diff, v, fields, label, props, idValue, versionField, e)
+	} else {
+		e.id = NodeID(idValue.Convert(reflect.TypeOf(uint64(0))).Uint())
+		e.ref = diff.session.Node(e.id)
+		err = this.diffUpdate(diff, v, fields, label, props, versionField, e)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range fields {
+		if len(f.Rel) < 1 {
+			continue
+		}
+		err = this.diffRelationships(diff, e, f, relatedEntities(v.FieldByIndex(f.Index)), seen)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return e, nil
+}
+
+// queues the creation of a new entity's node and copies the new id back once applied
+func (this *Neo4j) diffCreate(diff *GraphDiff, v reflect.Value, fields []*entityField, label string, props map[string]interface{}, idValue reflect.Value, versionField *entityField, e *diffEntity) error {
+	err := this.validate(props)
+	if err != nil {
+		return err
+	}
+	err = this.validateLabels([]string{label}, props)
+	if err != nil {
+		return err
+	}
+	if versionField != nil {
+		props[versionField.Name] = 1
+	}
+	uuid, err := this.assignUUID(props)
+	if err != nil {
+		return err
+	}
+	props, err = this.encodeProperties(props)
+	if err != nil {
+		return err
+	}
+	job := diff.session.add("POST", "/node", props)
+	e.ref = Ref("{" + strconv.Itoa(job) + "}")
+	diff.Ops = append(diff.Ops, &DiffOp{Kind: DiffCreateNode, Ref: e.ref, Name: label, Value: props})
+	if len(label) > 0 {
+		diff.session.add("POST", string(e.ref)+"/labels", label)
+	}
+	diff.after = append(diff.after, func(results map[int]*BatchResult) error {
+		result, ok := results[job]
+		if !ok {
+			return errors.New("Batch returned no result for created node.")
+		}
+		id, err := idFromURL(result.Location)
+		if err != nil {
+			return err
+		}
+		e.id = NodeID(id)
+		err = assignValue(idValue, id)
+		if err != nil {
+			return err
+		}
+		if versionField != nil {
+			err = assignValue(v.FieldByIndex(versionField.Index), int64(1))
+			if err != nil {
+				return err
+			}
+		}
+		for _, f := range fields {
+			if len(uuid) > 0 && f.Name == this.UUIDProperty && !f.ID && len(f.Rel) < 1 {
+				err = this.FromNeo(uuid, v.FieldByIndex(f.Index).Addr().Interface())
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	return nil
+}
+
+// queues the property changes of a stored entity
+func (this *Neo4j) diffUpdate(diff *GraphDiff, v reflect.Value, fields []*entityField, label string, props map[string]interface{}, versionField *entityField, e *diffEntity) error {
+	node, err := this.GetNode(e.id)
+	if err != nil {
+		return err
+	}
+	version := int64(0)
+	if versionField != nil {
+		delete(props, versionField.Name)
+		current := int64(0)
+		err = assignValue(reflect.ValueOf(&current).Elem(), v.FieldByIndex(versionField.Index).Interface())
+		if err != nil {
+			return err
+		}
+		if sv, ok := node.Data[versionField.Name]; ok {
+			err = assignValue(reflect.ValueOf(&version).Elem(), sv)
+			if err != nil {
+				return err
+			}
+		}
+		if version != current {
+			return ErrVersionConflict
+		}
+	}
+	encoded, err := this.encodeProperties(props)
+	if err != nil {
+		return err
+	}
+	changed := map[string]interface{}{}
+	ops := []*DiffOp{}
+	for _, f := range fields {
+		if f.ID || f.Version || len(f.Rel) > 0 {
+			continue
+		}
+		for _, name := range this.diffKeys(f.Name, encoded, node.Data) {
+			value, want := encoded[name]
+			stored, have := node.Data[name]
+			switch {
+			case want && value != nil && (!have || !sameValue(stored, value)):
+				raw, ok := props[name]
+				if !ok { // flattened key of a map field
+					raw = value
+				}
+				changed[name] = raw
+				ops = append(ops, &DiffOp{Kind: DiffSetProperty, Ref: e.ref, Name: name, Value: value})
+			case (!want || value == nil) && have:
+				changed[name] = nil
+				ops = append(ops, &DiffOp{Kind: DiffRemoveProperty, Ref: e.ref, Name: name})
+			}
+		}
+	}
+	if len(ops) < 1 {
+		return nil
+	}
+	err = this.validate(changed)
+	if err != nil {
+		return err
+	}
+	err = this.validateLabels([]string{label}, changed)
+	if err != nil {
+		return err
+	}
+	diff.Ops = append(diff.Ops, ops...)
+	if versionField != nil {
+		job := diff.session.add("POST", "/cypher", this.versionedUpdate(e.id, ops, versionField.Name, version))
+		diff.after = append(diff.after, func(results map[int]*BatchResult) error {
+			updated, err := updatedCount(results[job])
+			if err != nil {
+				return err
+			}
+			if updated < 1 { // changed since Diff read it
+				return ErrVersionConflict
+			}
+			return assignValue(v.FieldByIndex(versionField.Index), version+1)
+		})
+		return nil
+	}
+	for _, op := range ops {
+		if op.Kind == DiffSetProperty {
+			diff.session.add("PUT", string(e.ref)+"/properties/"+op.Name, op.Value)
+		} else {
+			diff.session.add("DELETE", string(e.ref)+"/properties/"+op.Name, nil)
+		}
+	}
+	return nil
+}
+
+// names field is stored under: itself, and with Flatten set the flattened keys of a map field found in any of maps, ie: address.city
+func (this *Neo4j) diffKeys(name string, maps ...map[string]interface{}) []string {
+	keys := []string{name}
+	if len(this.Flatten) < 1 {
+		return keys
+	}
+	seen := map[string]bool{name: true}
+	for _, m := range maps {
+		for k := range m {
+			if !seen[k] && strings.HasPrefix(k, name+this.Flatten) {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys[1:])
+	return keys
+}
+
+// body of a cypher batch job applying ops to node id only while its version property still equals version, incrementing it
+// the version is checked again inside the batch, so a write committed after Diff read the node isn't overwritten.
+// the job returns the number of nodes it updated, 0 when the check didn't match, see updatedCount
+func (this *Neo4j) versionedUpdate(id NodeID, ops []*DiffOp, name string, version int64) map[string]interface{} {
+	prop := "n." + this.cypherName(name)
+	set := map[string]interface{}{}
+	removes := []string{}
+	for _, op := range ops {
+		if op.Kind == DiffSetProperty {
+			set[op.Name] = op.Value
+		} else {
+			removes = append(removes, "n."+this.cypherName(op.Name))
+		}
+	}
+	query := "START n=node({id}) SET n.`_lock_` = true REMOVE n.`_lock_` " + // write lock before reading the version
+		"WITH n WHERE coalesce(" + prop + ", 0) = {version} " +
+		"SET n += {props}, " + prop + " = {version} + 1 "
+	if len(removes) > 0 {
+		query += "REMOVE " + strings.Join(removes, ", ") + " "
+	}
+	query += "RETURN count(n) AS updated"
+	params := map[string]interface{}{"id": id, "version": version, "props": set}
+	return map[string]interface{}{"query": query, "params": params}
+}
+
+// the number of nodes a versionedUpdate job updated, read from its cypher result
+func updatedCount(result *BatchResult) (int64, error) {
+	var res struct {
+		Data [][]int64 `json:"data"`
+	}
+	if result == nil || json.Unmarshal(result.Body, &res) != nil || len(res.Data) < 1 || len(res.Data[0]) < 1 {
+		return 0, errors.New("Unable to read the result of the version check.")
+	}
+	return res.Data[0][0], nil
+}
+
+// queues the relationships of field f missing from the stored graph and the deletion of stored ones no longer referenced
+func (this *Neo4j) diffRelationships(diff *GraphDiff, e *diffEntity, f *entityField, others []reflect.Value, seen map[uintptr]*diffEntity) error {
+	stored := map[NodeID][]RelID{} // other end -> relationships
+	if !e.new {
+		rels, err := this.GetRelationshipsOfTypes(e.id, f.Rel, f.Name)
+		if err != nil {
+			return err
+		}
+		for _, rel := range rels {
+			end := rel.End
+			if f.Rel == DirIn {
+				end = rel.Start
+			}
+			id, err := idFromURL(end)
+			if err != nil {
+				return err
+			}
+			stored[NodeID(id)] = append(stored[NodeID(id)], rel.RelID())
+		}
+	}
+	for _, other := range others {
+		o, err := this.diff(diff, other, seen)
+		if err != nil {
+			return err
+		}
+		if !o.new && len(stored[o.id]) > 0 {
+			stored[o.id] = stored[o.id][1:] // keep one, extra duplicates get deleted below
+			continue
+		}
+		src, dst := e.ref, o.ref
+		if f.Rel == DirIn {
+			src, dst = o.ref, e.ref
+		}
+		diff.session.add("POST", string(src)+"/relationships", map[string]interface{}{"to": string(dst), "type": f.Name})
+		diff.Ops = append(diff.Ops, &DiffOp{Kind: DiffCreateRelationship, Ref: src, Name: f.Name, Value: dst})
+	}
+	for _, ids := range stored {
+		for _, id := range ids {
+			ref := diff.session.Relationship(id)
+			diff.session.Delete(ref)
+			diff.Ops = append(diff.Ops, &DiffOp{Kind: DiffDelRelationship, Ref: ref, Name: f.Name})
+		}
+	}
+	return nil
+}
+
+/*
+Len() returns the number of changes in the diff
+*/
+func (this *GraphDiff) Len() int {
+	return len(this.Ops)
+}
+
+/*
+Apply() returns any errors raised as error
+sends every change as a single batch, so either all of them are applied or none. ids of created nodes are set on their entities
+the versions of versioned entities are checked again inside the batch: when one was changed since Diff read it its properties are left as they are and ErrVersionConflict is returned
+the other changes of the batch are applied all the same
+*/
+func (this *GraphDiff) Apply() error {
+	if this.session.Len() < 1 {
+		return nil
+	}
+	results, err := this.session.flush("GraphDiff.Apply")
+	if err != nil {
+		return err
+	}
+	var conflict error
+	for _, f := range this.after {
+		err = f(results)
+		if errors.Is(err, ErrVersionConflict) {
+			conflict = err // the rest was applied, ids still have to be set
+		} else if err != nil {
+			return err
+		}
+	}
+	this.after = nil
+	return conflict
+}
+
+// compares a property value as read from neo4j with one about to be written
+func sameValue(stored interface{}, value interface{}) bool {
+	a, errA := json.Marshal(stored)
+	b, errB := json.Marshal(value)
+	return errA == nil && errB == nil && string(a) == string(b)
+}
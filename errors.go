@@ -0,0 +1,264 @@
+package neo4j
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// what a 4xx response means, errors returned for them match these with errors.Is. ie: errors.Is(err, neo4j.ErrNotFound)
+var (
+	ErrBadRequest = errors.New("Invalid data sent.") // 400
+	ErrNotFound   = errors.New("Not found.")         // 404
+	ErrConflict   = errors.New("Conflict.")          // 409
+)
+
+// the sentinel error matching a status code, nil when there is none
+func statusSentinel(status int) error {
+	switch status {
+	case 400:
+		return ErrBadRequest
+	case 404:
+		return ErrNotFound
+	case 409:
+		return ErrConflict
+	}
+	return nil
+}
+
+// an error with a message of its own that matches err with errors.Is
+type wrapError struct {
+	msg string
+	err error
+}
+
+func (this *wrapError) Error() string {
+	return this.msg
+}
+
+func (this *wrapError) Unwrap() error {
+	return this.err
+}
+
+// most of an error body that is read, the stacktrace of a neo4j exception can get long
+const maxErrorBody = 64 << 10
+
+// error response of the server, returned for 4xx and 5xx responses and for other statuses a call treats as an error, ie: 204 from GetProperty
+// match it against ErrNotFound, ErrBadRequest and ErrConflict with errors.Is, get at the fields with errors.As
+type ServerError struct {
+	Status     int      // http status code of the response
+	Message    string   // message of the exception thrown on the server
+	Exception  string   // name of the exception, ie: NodeNotFoundException
+	Fullname   string   // java class name of the exception
+	Code       string   // neo4j status code, ie: Neo.ClientError.Schema.ConstraintViolation. 2.x servers and later
+	Stacktrace []string // stacktrace of the exception, when the server sends it
+	Err        error    // what the client makes of the status code, ie: "Node not found."
+	Op         string   // client method the request was sent for, ie: Neo4j.GetNode
+	Method     string   // http method of the request
+	URL        string   // url of the request, any password in it redacted
+}
+
+func (this *ServerError) Error() string {
+	if len(this.Method) < 1 {
+		return this.message()
+	}
+	prefix := this.Method + " " + this.URL + ": "
+	if len(this.Op) > 0 {
+		prefix = this.Op + ": " + prefix
+	}
+	return prefix + this.message()
+}
+
+// the error without the request it belongs to
+func (this *ServerError) message() string {
+	msg := this.Message
+	if this.Err != nil {
+		if len(msg) < 1 {
+			return this.Err.Error()
+		}
+		return this.Err.Error() + " " + msg
+	}
+	if len(msg) < 1 {
+		return "Server returned status " + strconv.Itoa(this.Status) + "."
+	}
+	return msg
+}
+
+/*
+StatusCode() returns the http status code of the response
+get at it from any error the client returns with errors.As, ie: var serr *neo4j.ServerError; if errors.As(err, &serr) && serr.StatusCode() == 404 {...}
+*/
+func (this *ServerError) StatusCode() int {
+	return this.Status
+}
+
+// makes errors.Is and errors.As look at Err as well
+func (this *ServerError) Unwrap() error {
+	return this.Err
+}
+
+// makes errors.Is match the sentinel error of the status code, see ErrNotFound
+func (this *ServerError) Is(target error) bool {
+	sentinel := statusSentinel(this.Status)
+	return sentinel != nil && target == sentinel
+}
+
+// parses the json error body neo4j sends along with a 4xx or 5xx response, fields it can't find are left blank
+func newServerError(status int, body []byte, err error) *ServerError {
+	e := &ServerError{Status: status, Err: err}
+	var raw struct {
+		Message    string   `json:"message"`
+		Exception  string   `json:"exception"`
+		Fullname   string   `json:"fullname"`
+		Stacktrace []string `json:"stacktrace"`
+		Errors     []struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if json.Unmarshal(body, &raw) != nil {
+		return e
+	}
+	e.Message, e.Exception, e.Fullname, e.Stacktrace = raw.Message, raw.Exception, raw.Fullname, raw.Stacktrace
+	if len(raw.Errors) > 0 {
+		e.Code = raw.Errors[0].Code
+		if len(e.Message) < 1 {
+			e.Message = raw.Errors[0].Message
+		}
+	}
+	return e
+}
+
+/*
+MapStatus(status int, err error) returns an Option changing the error every call returns for status
+a nil err treats status as success: the call returns no error and an empty result, ie: MapStatus(404, nil) makes GetNode return nil, nil for a missing node
+*/
+func MapStatus(status int, err error) Option {
+	return func(neo *Neo4j) error {
+		neo.statusErrors = neo.mappedStatus(status, err)
+		return nil
+	}
+}
+
+/*
+WithStatus(status int, err error) returns a copy of the client returning err for status, see MapStatus
+for a single call: neo.WithStatus(404, nil).GetNode(id)
+*/
+func (this *Neo4j) WithStatus(status int, err error) *Neo4j {
+	neo := *this
+	neo.statusErrors = this.mappedStatus(status, err)
+	return &neo
+}
+
+// a copy of the status mapping with status mapped to err, copies of the client may share the old one
+func (this *Neo4j) mappedStatus(status int, err error) map[int]error {
+	m := make(map[int]error, len(this.statusErrors)+1)
+	for k, v := range this.statusErrors {
+		m[k] = v
+	}
+	m[status] = err
+	return m
+}
+
+// whether an error status, or 204 which has no body, is mapped to success. such responses are answered as if the server sent null
+func (this *Neo4j) ignored(status int) bool {
+	err, ok := this.statusErrors[status]
+	return ok && err == nil && (status >= 400 || status == 204)
+}
+
+// returns the error for resp, every 4xx and 5xx status gives a *ServerError carrying the parsed body and what the request was
+// errorList holds the message the client gives a status, it ends up in ServerError.Err. MapStatus overrides it
+func (this *Neo4j) statusError(op string, errorList map[int]error, resp *http.Response, body string) error {
+	status := resp.StatusCode
+	err, mapped := this.statusErrors[status]
+	if !mapped {
+		err = this.NewError(errorList, status)
+	}
+	if err == nil && (mapped || status < 400) {
+		return nil
+	}
+	e := newServerError(status, []byte(body), err)
+	e.Op = op
+	if resp.Request != nil {
+		e.Method, e.URL = resp.Request.Method, resp.Request.URL.Redacted()
+	}
+	return constraintViolation(e)
+}
+
+// same as statusError for a response whose body is still unread, reads the body when there is an error
+func (this *Neo4j) responseError(op string, errorList map[int]error, resp *http.Response) error {
+	if _, ok := this.statusErrors[resp.StatusCode]; ok || resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return this.statusError(op, errorList, resp, string(body))
+	}
+	return this.NewError(errorList, resp.StatusCode)
+}
+
+// a write refused because of a uniqueness constraint, an index uniqueness check or another 409 conflict
+// Label and Property are blank unless the server names them in its message
+type ConstraintViolationError struct {
+	*ServerError
+	Label    string
+	Property string
+}
+
+// makes errors.As find the ServerError underneath, errors.Is matches ErrConflict through it for a 409
+func (this *ConstraintViolationError) Unwrap() error {
+	return this.ServerError
+}
+
+// finds label and property in messages like: Node(0) already exists with label `Person` and property `name` = 'Alice'
+var constraintMessage = regexp.MustCompile("label [`\"']?([^`\"'\\s]+)[`\"']? and propert(?:y|ies) [`\"'(]?([^`\"'\\s=,)]+)")
+
+// returns e as a *ConstraintViolationError when it is one, e itself otherwise
+func constraintViolation(e *ServerError) error {
+	if e.Status != 409 && !strings.Contains(e.Exception, "ConstraintViolation") && !strings.Contains(e.Code, "ConstraintV") { // ConstraintViolation & ConstraintValidationFailed
+		return e
+	}
+	violation := &ConstraintViolationError{ServerError: e}
+	if m := constraintMessage.FindStringSubmatch(e.Message); m != nil {
+		violation.Label, violation.Property = m[1], m[2]
+	}
+	return violation
+}
+
+/*
+IsTransient(err error) returns whether err may go away when the request is sent again
+true for timeouts, connections refused, reset or cut off, 5xx responses and errors neo4j reports as Neo.TransientError.*
+false for 4xx responses, cancelled contexts and anything else that will fail the same way next time
+*/
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var serr *ServerError
+	if errors.As(err, &serr) {
+		if strings.HasPrefix(serr.Code, "Neo.TransientError.") {
+			return true
+		}
+		return serr.Status >= 500 && !strings.HasPrefix(serr.Code, "Neo.ClientError.")
+	}
+	if errors.Is(err, ErrCircuitOpen) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		return true
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNABORTED) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var nerr net.Error
+	return errors.As(err, &nerr) && nerr.Timeout()
+}
+
+// an error of the transport with the operation it happened in, the method and url are in err already
+func operationError(op string, err error) error {
+	if len(op) < 1 {
+		return err
+	}
+	return &wrapError{op + ": " + err.Error(), err}
+}
@@ -0,0 +1,77 @@
+package neo4j
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// the error bodies of 1.x, 2.x and a proxy in front of the server, each has to end up in the fields of ServerError
+func TestServerErrorParsing(t *testing.T) {
+	tests := []struct {
+		status    int
+		body      string
+		sentinel  error
+		message   string
+		exception string
+		code      string
+		transient bool
+	}{
+		{404, `{"message": "Cannot find node with id [5] in database.", "exception": "NodeNotFoundException", "fullname": "org.neo4j.server.rest.web.NodeNotFoundException", "stacktrace": ["a", "b"]}`,
+			ErrNotFound, "Cannot find node with id [5] in database.", "NodeNotFoundException", "", false},
+		{400, `{"errors": [{"code": "Neo.ClientError.Request.Invalid", "message": "Could not parse the request."}]}`,
+			ErrBadRequest, "Could not parse the request.", "", "Neo.ClientError.Request.Invalid", false},
+		{503, `{"errors": [{"code": "Neo.TransientError.General.DatabaseUnavailable", "message": "Database unavailable."}]}`,
+			nil, "Database unavailable.", "", "Neo.TransientError.General.DatabaseUnavailable", true},
+		{502, `<html>Bad Gateway</html>`, nil, "", "", "", true},
+	}
+	for _, test := range tests {
+		test := test
+		neo := newStubClient(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(test.status)
+			fmt.Fprint(w, test.body)
+		})
+		_, err := neo.GetNode(5)
+		var serr *ServerError
+		if !errors.As(err, &serr) {
+			t.Fatalf("%d: got %v, want a *ServerError", test.status, err)
+		}
+		if serr.StatusCode() != test.status || serr.Message != test.message || serr.Exception != test.exception || serr.Code != test.code {
+			t.Errorf("%d: got status %d, message %q, exception %q, code %q", test.status, serr.StatusCode(), serr.Message, serr.Exception, serr.Code)
+		}
+		if serr.Op != "Neo4j.GetNode" || serr.Method != "GET" || !strings.HasSuffix(serr.URL, "/db/data/node/5") {
+			t.Errorf("%d: got op %q, method %q, url %q", test.status, serr.Op, serr.Method, serr.URL)
+		}
+		for _, sentinel := range []error{ErrNotFound, ErrBadRequest, ErrConflict} {
+			want := sentinel == test.sentinel
+			if errors.Is(err, sentinel) != want {
+				t.Errorf("%d: errors.Is(err, %v) is %v, want %v", test.status, sentinel, !want, want)
+			}
+		}
+		if IsTransient(err) != test.transient {
+			t.Errorf("%d: IsTransient is %v, want %v", test.status, !test.transient, test.transient)
+		}
+	}
+}
+
+// a 409 naming the label and property it conflicts on comes back as a *ConstraintViolationError
+func TestConstraintViolation(t *testing.T) {
+	neo := newStubClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(409)
+		fmt.Fprint(w, `{"errors": [{"code": "Neo.ClientError.Schema.ConstraintValidationFailed", "message": "Node(0) already exists with label `+"`Person`"+` and property `+"`name`"+` = 'Alice'"}]}`)
+	})
+	_, err := neo.Cypher("CREATE (n:Person {name: 'Alice'})", nil)
+	var violation *ConstraintViolationError
+	if !errors.As(err, &violation) {
+		t.Fatalf("got %v, want a *ConstraintViolationError", err)
+	}
+	if violation.Label != "Person" || violation.Property != "name" {
+		t.Errorf("got label %q and property %q, want Person and name", violation.Label, violation.Property)
+	}
+	var serr *ServerError
+	if !errors.Is(err, ErrConflict) || !errors.As(err, &serr) || serr.Op != "Neo4j.Cypher" {
+		t.Errorf("got %v, want ErrConflict from a *ServerError of Neo4j.Cypher", err)
+	}
+}
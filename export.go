@@ -0,0 +1,205 @@
+package neo4j
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// identifiers that can be used in cypher without backticks
+var cypherIdent = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+/*
+ExportCypher(w io.Writer, depth int, merge bool, node ids ...uint) returns any errors raised as error
+walks out from the given nodes following relationships in both directions up to depth hops (depth < 0 walks the whole connected graph)
+and writes a single cypher statement recreating the nodes with their labels and every relationship between them, depth 0 exports just the given nodes
+merge writes MERGE clauses instead of CREATE so re-running the statement doesn't duplicate anything
+nodes are merged on their UUID, see UseUUIDs, and the rest of their properties set. merge fails for nodes without one, as no other property tells nodes apart
+*/
+func (this *Neo4j) ExportCypher(w io.Writer, depth int, merge bool, ids ...NodeID) error {
+	var (
+		nodes  []*NeoTemplate
+		rels   []*NeoTemplate
+		labels = map[uint64]string{} // node id -> :Label:Label
+	)
+	seen := map[NodeID]bool{}
+	seenRel := map[uint64]bool{}
+	queue := append([]NodeID{}, ids...)
+	for _, id := range ids {
+		seen[id] = true
+	}
+	for level := 0; len(queue) > 0; level++ {
+		next := []NodeID{}
+		for _, id := range queue {
+			node, err := this.GetNode(id)
+			if err != nil {
+				return err
+			}
+			nodes = append(nodes, node)
+			names, err := this.GetLabels(id)
+			if err != nil {
+				return err
+			}
+			for _, name := range names {
+				labels[node.ID] += ":" + this.cypherName(name)
+			}
+			template, err := this.receiveTemplates("Neo4j.ExportCypher", "GET", node.RelationshipsAll, "", nil) // on the last level too, for the relationships between its nodes
+			if err != nil {
+				return err
+			}
+			for i := 0; i < len(template); i++ {
+				rel := template[i]
+				if !seenRel[rel.ID] {
+					seenRel[rel.ID] = true
+					rels = append(rels, rel)
+				}
+				if depth >= 0 && level >= depth {
+					continue // last level, relationships leading further out are not followed
+				}
+				for _, u := range []string{rel.Start, rel.End} {
+					other, err := idFromURL(u)
+					if err != nil {
+						return err
+					}
+					if !seen[NodeID(other)] {
+						seen[NodeID(other)] = true
+						next = append(next, NodeID(other))
+					}
+				}
+			}
+		}
+		if depth >= 0 && level >= depth {
+			break
+		}
+		queue = next
+	}
+	clause := "CREATE "
+	if merge {
+		clause = "MERGE "
+	}
+	lines := []string{}
+	for _, node := range nodes {
+		if !merge {
+			lines = append(lines, clause+"(n"+strconv.FormatUint(node.ID, 10)+labels[node.ID]+this.cypherMap(node.Data)+")")
+			continue
+		}
+		line, err := this.mergeNode(node, labels[node.ID])
+		if err != nil {
+			return err
+		}
+		lines = append(lines, line)
+	}
+	for _, rel := range rels {
+		start, _ := idFromURL(rel.Start)
+		end, _ := idFromURL(rel.End)
+		if !seen[NodeID(start)] || !seen[NodeID(end)] {
+			continue // other end is outside of the exported subgraph
+		}
+		lines = append(lines, clause+"(n"+strconv.FormatUint(start, 10)+")-[:"+this.cypherName(rel.Type)+this.cypherMap(rel.Data)+"]->(n"+strconv.FormatUint(end, 10)+")")
+	}
+	if len(lines) < 1 {
+		return nil
+	}
+	_, err := io.WriteString(w, strings.Join(lines, "\n")+";\n")
+	return err
+}
+
+// a MERGE clause matching node, carrying labels, on its UUID followed by a SET of its other properties
+func (this *Neo4j) mergeNode(node *NeoTemplate, labels string) (string, error) {
+	name := "n" + strconv.FormatUint(node.ID, 10)
+	uuid, ok := node.Data[this.UUIDProperty].(string)
+	if len(this.UUIDProperty) < 1 || !ok || len(uuid) < 1 {
+		return "", errors.New("Node " + strconv.FormatUint(node.ID, 10) + " has no UUID to merge on, see UseUUIDs.")
+	}
+	line := "MERGE (" + name + labels + this.cypherMap(map[string]interface{}{this.UUIDProperty: uuid}) + ")"
+	sets := []string{}
+	for _, k := range this.sortedKeys(node.Data) {
+		if k != this.UUIDProperty {
+			sets = append(sets, name+"."+this.cypherName(k)+" = "+this.cypherLiteral(node.Data[k]))
+		}
+	}
+	if len(sets) > 0 {
+		line += " SET " + strings.Join(sets, ", ")
+	}
+	return line, nil
+}
+
+// formats a map of properties as a cypher map literal with a leading space, or nothing when empty
+func (this *Neo4j) cypherMap(data map[string]interface{}) string {
+	if len(data) < 1 {
+		return ""
+	}
+	parts := []string{}
+	for _, k := range this.sortedKeys(data) {
+		parts = append(parts, this.cypherName(k)+": "+this.cypherLiteral(data[k]))
+	}
+	return " {" + strings.Join(parts, ", ") + "}"
+}
+
+// backticks a label/type/property name when it isn't a plain identifier
+func (this *Neo4j) cypherName(s string) string {
+	if cypherIdent.MatchString(s) {
+		return s
+	}
+	return "`" + strings.Replace(s, "`", "``", -1) + "`"
+}
+
+// formats a property value as a cypher literal
+func (this *Neo4j) cypherLiteral(v interface{}) string {
+	switch vv := v.(type) {
+	case string:
+		return cypherString(vv)
+	case float64:
+		return strconv.FormatFloat(vv, 'g', -1, 64)
+	case bool:
+		return strconv.FormatBool(vv)
+	case nil:
+		return "null"
+	case []interface{}:
+		parts := make([]string, len(vv))
+		for i, e := range vv {
+			parts[i] = this.cypherLiteral(e)
+		}
+		return "[" + strings.Join(parts, ", ") + "]"
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "null"
+	}
+	return string(b)
+}
+
+// quotes s as a cypher string literal, which knows fewer escapes than a go one: control characters other than \t \b \n \r \f become \uXXXX
+func cypherString(s string) string {
+	buf := new(strings.Builder)
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\t':
+			buf.WriteString(`\t`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\f':
+			buf.WriteString(`\f`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				buf.WriteString(`\u` + strconv.FormatInt(0x10000+int64(r), 16)[1:])
+			} else {
+				buf.WriteRune(r)
+			}
+		}
+	}
+	buf.WriteByte('"')
+	return buf.String()
+}
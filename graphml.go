@@ -0,0 +1,307 @@
+package neo4j
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const graphmlNS = "http://graphml.graphdrawing.org/xmlns"
+
+// xml layout of a GraphML document, only the parts neo4j can make use of
+type graphml struct {
+	XMLName xml.Name     `xml:"graphml"`
+	Keys    []graphmlKey `xml:"key"`
+	Graph   graphmlGraph `xml:"graph"`
+}
+type graphmlKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+type graphmlGraph struct {
+	Nodes []graphmlElement `xml:"node"`
+	Edges []graphmlElement `xml:"edge"`
+}
+type graphmlElement struct {
+	ID     string        `xml:"id,attr"`
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Label  string        `xml:"label,attr"`
+	Data   []graphmlData `xml:"data"`
+}
+type graphmlData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+/*
+ExportGraphML(w io.Writer, node ids ...uint) returns any errors raised as error
+writes the given nodes and every relationship running between them as a GraphML document
+passing no ids exports the whole graph
+*/
+func (this *Neo4j) ExportGraphML(w io.Writer, ids ...NodeID) error {
+	nodes := make([]*NeoTemplate, 0, len(ids))
+	if len(ids) < 1 {
+		it := this.AllNodes(0)
+		for it.Next() {
+			nodes = append(nodes, it.Node())
+		}
+		if it.Err() != nil {
+			return it.Err()
+		}
+	}
+	for _, id := range ids {
+		node, err := this.GetNode(id)
+		if err != nil {
+			return err
+		}
+		nodes = append(nodes, node)
+	}
+	rels := []*NeoTemplate{}
+	wanted := map[NodeID]bool{}
+	for _, node := range nodes {
+		wanted[node.NodeID()] = true
+	}
+	for _, node := range nodes {
+		template, err := this.receiveTemplates("Neo4j.ExportGraphML", "GET", node.RelationshipsOut, "", nil) // outgoing only so every relationship is seen once
+		if err != nil {
+			return err
+		}
+		for i := 0; i < len(template); i++ {
+			end, err := idFromURL(template[i].End)
+			if err != nil {
+				return err
+			}
+			if wanted[NodeID(end)] {
+				rels = append(rels, template[i])
+			}
+		}
+	}
+	// GraphML needs every attribute declared up front along with its type
+	nodeKeys := this.graphmlKeys(nodes)
+	edgeKeys := this.graphmlKeys(rels)
+	buf := []string{xml.Header[:len(xml.Header)-1], `<graphml xmlns="` + graphmlNS + `">`}
+	for _, k := range this.sortedKeys(nodeKeys) {
+		buf = append(buf, `<key id="n_`+this.xmlEscape(k)+`" for="node" attr.name="`+this.xmlEscape(k)+`" attr.type="`+nodeKeys[k]+`"/>`)
+	}
+	for _, k := range this.sortedKeys(edgeKeys) {
+		buf = append(buf, `<key id="e_`+this.xmlEscape(k)+`" for="edge" attr.name="`+this.xmlEscape(k)+`" attr.type="`+edgeKeys[k]+`"/>`)
+	}
+	buf = append(buf, `<graph id="G" edgedefault="directed">`)
+	for _, node := range nodes {
+		buf = append(buf, `<node id="n`+strconv.FormatUint(node.ID, 10)+`">`+this.graphmlData("n_", node.Data)+`</node>`)
+	}
+	for _, rel := range rels {
+		start, _ := idFromURL(rel.Start)
+		end, _ := idFromURL(rel.End)
+		buf = append(buf, `<edge id="e`+strconv.FormatUint(rel.ID, 10)+`" source="n`+strconv.FormatUint(start, 10)+`" target="n`+strconv.FormatUint(end, 10)+`" label="`+this.xmlEscape(rel.Type)+`">`+this.graphmlData("e_", rel.Data)+`</edge>`)
+	}
+	buf = append(buf, `</graph>`, `</graphml>`, ``)
+	_, err := io.WriteString(w, strings.Join(buf, "\n"))
+	return err
+}
+
+/*
+ImportGraphML(r io.Reader, opts *ImportOptions) returns an ImportResult struct and any errors raised as error
+nodes are written first, then edges, in batches of opts.BatchSize. ImportResult.Nodes maps GraphML node ids to the new node ids
+values are converted to the attr.type of their key: boolean, int, long, float and double, anything else is stored as a string
+edges take their relationship type from the label attribute, falling back to a "label" data attribute, then to "RELATED_TO"
+*/
+func (this *Neo4j) ImportGraphML(r io.Reader, opts *ImportOptions) (*ImportResult, error) {
+	size := 500
+	if opts != nil && opts.BatchSize > 0 {
+		size = opts.BatchSize
+	}
+	result := &ImportResult{Nodes: map[string]NodeID{}}
+	doc := new(graphml)
+	err := xml.NewDecoder(r).Decode(doc)
+	if err != nil {
+		return result, err
+	}
+	keys := map[string]graphmlKey{}
+	for _, k := range doc.Keys {
+		keys[k.ID] = k
+	}
+	session := this.NewSession()
+	ids := []string{}
+	for _, n := range doc.Graph.Nodes {
+		props, err := this.graphmlProperties(keys, n.Data)
+		if err != nil {
+			return result, errors.New("Node " + n.ID + ": " + err.Error())
+		}
+		session.CreateNodeTyped(props)
+		ids = append(ids, n.ID)
+		if session.Len() >= size {
+			err = this.flushImport("Neo4j.ImportGraphML", session, result, ids)
+			if err != nil {
+				return result, err
+			}
+			ids = ids[:0]
+		}
+	}
+	err = this.flushImport("Neo4j.ImportGraphML", session, result, ids)
+	if err != nil {
+		return result, err
+	}
+	for _, e := range doc.Graph.Edges {
+		src, ok := result.Nodes[e.Source]
+		if !ok {
+			return result, errors.New("Edge source " + e.Source + " not found.")
+		}
+		dst, ok := result.Nodes[e.Target]
+		if !ok {
+			return result, errors.New("Edge target " + e.Target + " not found.")
+		}
+		props, err := this.graphmlProperties(keys, e.Data)
+		if err != nil {
+			return result, errors.New("Edge " + e.ID + ": " + err.Error())
+		}
+		rType := e.Label
+		if len(rType) < 1 {
+			rType, _ = props["label"].(string)
+			delete(props, "label")
+		}
+		if len(rType) < 1 {
+			rType = "RELATED_TO"
+		}
+		session.CreateRelationshipTyped(session.Node(src), session.Node(dst), props, rType)
+		if session.Len() >= size {
+			err = this.flushImport("Neo4j.ImportGraphML", session, result, nil)
+			if err != nil {
+				return result, err
+			}
+		}
+	}
+	return result, this.flushImport("Neo4j.ImportGraphML", session, result, nil)
+}
+
+// works out the GraphML attr.type of every property found on the templates, numbers are long when every value of the key is integral
+func (this *Neo4j) graphmlKeys(templates []*NeoTemplate) map[string]string {
+	keys := map[string]string{}
+	for _, t := range templates {
+		for k, v := range t.Data {
+			kind := "string"
+			switch vv := v.(type) {
+			case float64:
+				kind = "double"
+				if integral(vv) {
+					kind = "long"
+				}
+			case bool:
+				kind = "boolean"
+			}
+			prev, ok := keys[k]
+			switch {
+			case !ok || prev == kind:
+			case (prev == "long" || prev == "double") && (kind == "long" || kind == "double"):
+				kind = "double" // some values have a fraction
+			default:
+				kind = "string" // mixed types, string can hold them all
+			}
+			keys[k] = kind
+		}
+	}
+	return keys
+}
+
+// builds the <data> elements for a node/edge
+func (this *Neo4j) graphmlData(prefix string, data map[string]interface{}) string {
+	out := ""
+	for _, k := range this.sortedKeys(data) {
+		out += `<data key="` + prefix + this.xmlEscape(k) + `">` + this.xmlEscape(this.propertyString(data[k])) + `</data>`
+	}
+	return out
+}
+
+// converts <data> elements back into properties of the attr.type their key declares, undeclared keys are taken as strings
+func (this *Neo4j) graphmlProperties(keys map[string]graphmlKey, data []graphmlData) (map[string]interface{}, error) {
+	props := map[string]interface{}{}
+	for _, d := range data {
+		k, ok := keys[d.Key]
+		name := k.Name
+		if len(name) < 1 {
+			name = d.Key
+		}
+		if !ok {
+			props[name] = d.Value
+			continue
+		}
+		v, err := graphmlValue(k.Type, d.Value)
+		if err != nil {
+			return nil, errors.New("Attribute " + name + ": " + err.Error())
+		}
+		props[name] = v
+	}
+	return props, nil
+}
+
+// parses a <data> value of GraphML attr.type kind
+func graphmlValue(kind string, s string) (interface{}, error) {
+	switch kind {
+	case "boolean":
+		return strconv.ParseBool(strings.TrimSpace(s))
+	case "int", "long":
+		return strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	case "float", "double":
+		return strconv.ParseFloat(strings.TrimSpace(s), 64)
+	}
+	return s, nil
+}
+
+// whether f is a whole number a float64 holds exactly, ie: one neo4j stored as a long
+func integral(f float64) bool {
+	return f == math.Trunc(f) && math.Abs(f) <= 1<<53
+}
+
+// formats a property value as returned by the json decoder, whole numbers without exponent so they parse as a long
+func (this *Neo4j) propertyString(v interface{}) string {
+	switch vv := v.(type) {
+	case string:
+		return vv
+	case float64:
+		if integral(vv) {
+			return strconv.FormatInt(int64(vv), 10)
+		}
+		return strconv.FormatFloat(vv, 'g', -1, 64)
+	case bool:
+		return strconv.FormatBool(vv)
+	case nil:
+		return ""
+	}
+	b, err := json.Marshal(v) // arrays
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+// returns the keys of a map in a stable order
+func (this *Neo4j) sortedKeys(m interface{}) []string {
+	keys := []string{}
+	switch mm := m.(type) {
+	case map[string]string:
+		for k := range mm {
+			keys = append(keys, k)
+		}
+	case map[string]interface{}:
+		for k := range mm {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// escapes s for use in xml text and attribute values
+func (this *Neo4j) xmlEscape(s string) string {
+	buf := new(strings.Builder)
+	xml.EscapeText(buf, []byte(s))
+	return buf.String()
+}
@@ -0,0 +1,145 @@
+package neo4j
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+)
+
+// url of the labels on a node
+func (this *Neo4j) labelsURL(id NodeID) string {
+	return this.endpoint("node", "/node") + "/" + id.String() + "/labels"
+}
+
+/*
+ListLabels() returns every label in use in the database and any errors raised as error
+*/
+func (this *Neo4j) ListLabels() ([]string, error) {
+	return this.getStrings("Neo4j.ListLabels", this.endpoint("node_labels", "/labels"), map[int]error{})
+}
+
+// fetches a json array of strings
+func (this *Neo4j) getStrings(op string, url string, errorList map[int]error) ([]string, error) {
+	body, resp, err := this.send(op, "GET", url, "")
+	if err != nil {
+		return nil, err
+	}
+	err = this.statusError(op, errorList, resp, body)
+	if err != nil {
+		return nil, err
+	}
+	list := []string{}
+	err = json.Unmarshal([]byte(body), &list)
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+/*
+GetLabels(node id uint) returns the labels on the node and any errors raised as error
+*/
+func (this *Neo4j) GetLabels(id NodeID) ([]string, error) {
+	errorList := map[int]error{
+		404: errors.New("Node not found."),
+	}
+	return this.getStrings("Neo4j.GetLabels", this.labelsURL(id), errorList)
+}
+
+/*
+AddLabels(node id uint, labels ...string) returns any errors raised as error
+labels already on the node are left alone
+*/
+func (this *Neo4j) AddLabels(id NodeID, labels ...string) error {
+	if len(labels) < 1 {
+		return nil
+	}
+	s, err := json.Marshal(labels)
+	if err != nil {
+		return errors.New("Unable to Marshal Json data")
+	}
+	body, resp, err := this.send("Neo4j.AddLabels", "POST", this.labelsURL(id), string(s))
+	if err != nil {
+		return err
+	}
+	errorList := map[int]error{
+		404: errors.New("Node not found."),
+		400: errors.New("Invalid label name."),
+	}
+	return this.statusError("Neo4j.AddLabels", errorList, resp, body)
+}
+
+/*
+SetLabels(node id uint, labels ...string) returns any errors raised as error
+replaces every label on the node with labels, passing none removes them all
+*/
+func (this *Neo4j) SetLabels(id NodeID, labels ...string) error {
+	if labels == nil {
+		labels = []string{} // json null isn't accepted, an empty array is
+	}
+	s, err := json.Marshal(labels)
+	if err != nil {
+		return errors.New("Unable to Marshal Json data")
+	}
+	body, resp, err := this.send("Neo4j.SetLabels", "PUT", this.labelsURL(id), string(s))
+	if err != nil {
+		return err
+	}
+	errorList := map[int]error{
+		404: errors.New("Node not found."),
+		400: errors.New("Invalid label name."),
+	}
+	return this.statusError("Neo4j.SetLabels", errorList, resp, body)
+}
+
+/*
+RemoveLabel(node id uint, label string) returns any errors raised as error
+*/
+func (this *Neo4j) RemoveLabel(id NodeID, label string) error {
+	if len(label) < 1 {
+		return errors.New("Label must be at least 1 character.")
+	}
+	body, resp, err := this.send("Neo4j.RemoveLabel", "DELETE", this.labelsURL(id)+"/"+url.PathEscape(label), "")
+	if err != nil {
+		return err
+	}
+	errorList := map[int]error{
+		404: errors.New("Node not found."),
+	}
+	return this.statusError("Neo4j.RemoveLabel", errorList, resp, body)
+}
+
+/*
+GetNodesByLabel(label string) returns every node carrying the label and any errors raised as error
+*/
+func (this *Neo4j) GetNodesByLabel(label string) ([]*NeoTemplate, error) {
+	return this.labelNodes("Neo4j.GetNodesByLabel", label, "")
+}
+
+/*
+GetNodesByLabelAndProperty(label string, key string, value interface{}) returns the nodes carrying the label whose property matches value and any errors raised as error
+value is json encoded before it is sent, so "42" and 42 find different nodes
+*/
+func (this *Neo4j) GetNodesByLabelAndProperty(label string, key string, value interface{}) ([]*NeoTemplate, error) {
+	key = strings.TrimSpace(key)
+	if len(key) < 1 {
+		return nil, errors.New("Property name must be at least 1 character.")
+	}
+	s, err := json.Marshal(value)
+	if err != nil {
+		return nil, errors.New("Unable to Marshal Json data")
+	}
+	return this.labelNodes("Neo4j.GetNodesByLabelAndProperty", label, "?"+url.QueryEscape(key)+"="+url.QueryEscape(string(s)))
+}
+
+// fetches /label/{name}/nodes with an optional query string
+func (this *Neo4j) labelNodes(op string, label string, query string) ([]*NeoTemplate, error) {
+	if len(label) < 1 {
+		return nil, errors.New("Label must be at least 1 character.")
+	}
+	errorList := map[int]error{
+		400: errors.New("Invalid data sent."),
+	}
+	return this.receiveTemplates(op, "GET", this.endpoint("label", "/label")+"/"+url.PathEscape(label)+"/nodes"+query, "", errorList)
+}
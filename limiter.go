@@ -0,0 +1,91 @@
+package neo4j
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// consulted before every request is sent, golang.org/x/time/rate.Limiter satisfies it
+type Limiter interface {
+	Wait(ctx context.Context) error // blocks until the request may be sent, returns an error when ctx ends first
+}
+
+/*
+Limit(l Limiter) returns an Option making every request wait for l before it is sent, retries included
+the limiter is shared by every copy of the client, so goroutines in a Bulk are throttled together
+*/
+func Limit(l Limiter) Option {
+	return func(neo *Neo4j) error {
+		neo.limiter = l
+		return nil
+	}
+}
+
+/*
+RateLimit(perSecond float64, burst int) returns an Option sending at most perSecond requests a second, with bursts of up to burst requests
+see NewTokenBucket
+*/
+func RateLimit(perSecond float64, burst int) Option {
+	return func(neo *Neo4j) error {
+		l, err := NewTokenBucket(perSecond, burst)
+		if err != nil {
+			return err
+		}
+		return Limit(l)(neo)
+	}
+}
+
+// a Limiter refilling perSecond tokens a second up to burst, every request takes one
+type TokenBucket struct {
+	mu       sync.Mutex
+	interval time.Duration // time it takes to refill a single token
+	burst    int
+	tokens   float64
+	last     time.Time // when tokens was last brought up to date
+}
+
+/*
+NewTokenBucket(perSecond float64, burst int) returns a full TokenBucket and any errors raised as error
+*/
+func NewTokenBucket(perSecond float64, burst int) (*TokenBucket, error) {
+	if perSecond <= 0 {
+		return nil, errors.New("Rate must be above 0.")
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &TokenBucket{interval: time.Duration(float64(time.Second) / perSecond), burst: burst, tokens: float64(burst), last: time.Now()}, nil
+}
+
+/*
+Wait(ctx context.Context) returns any errors raised as error
+takes a token, waiting for one to be refilled when the bucket is empty. the error of ctx is returned when it ends first
+*/
+func (this *TokenBucket) Wait(ctx context.Context) error {
+	this.mu.Lock()
+	now := time.Now()
+	this.tokens += float64(now.Sub(this.last)) / float64(this.interval)
+	if this.tokens > float64(this.burst) {
+		this.tokens = float64(this.burst)
+	}
+	this.last = now
+	this.tokens-- // taken now, a negative count reserves tokens not refilled yet so waiters queue up in order
+	wait := time.Duration(-this.tokens * float64(this.interval))
+	this.mu.Unlock()
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		this.mu.Lock()
+		this.tokens++ // hand the reservation back
+		this.mu.Unlock()
+		return ctx.Err()
+	}
+}
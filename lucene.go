@@ -0,0 +1,116 @@
+package neo4j
+
+import (
+	"net/url"
+	"strings"
+)
+
+// a lucene query for SearchIdx, build it from the Lucene* functions so values are escaped properly
+// Lucene query lang: http://lucene.apache.org/java/3_1_0/queryparsersyntax.html
+type Lucene string
+
+// characters with a meaning in the lucene query syntax
+const luceneSpecialChars = `+-&|!(){}[]^"~*?:\/ `
+
+/*
+LuceneEscape(s string) returns s with every lucene special character backslash escaped
+*/
+func LuceneEscape(s string) string {
+	if strings.IndexAny(s, luceneSpecialChars) == -1 {
+		return s
+	}
+	buf := new(strings.Builder)
+	for _, r := range s {
+		if strings.ContainsRune(luceneSpecialChars, r) {
+			buf.WriteByte('\\')
+		}
+		buf.WriteRune(r)
+	}
+	return buf.String()
+}
+
+/*
+LuceneTerm(field string, value string) returns a query matching field exactly against value
+*/
+func LuceneTerm(field string, value string) Lucene {
+	return Lucene(LuceneEscape(field) + ":" + LuceneEscape(value))
+}
+
+/*
+LucenePrefix(field string, prefix string) returns a query matching values of field starting with prefix
+*/
+func LucenePrefix(field string, prefix string) Lucene {
+	return Lucene(LuceneEscape(field) + ":" + LuceneEscape(prefix) + "*")
+}
+
+/*
+LucenePhrase(field string, phrase string) returns a query matching the words of phrase next to each other in field
+*/
+func LucenePhrase(field string, phrase string) Lucene {
+	return Lucene(LuceneEscape(field) + `:"` + strings.Replace(strings.Replace(phrase, `\`, `\\`, -1), `"`, `\"`, -1) + `"`)
+}
+
+/*
+LuceneRange(field string, from string, to string, inclusive bool) returns a query matching values of field between from and to
+*/
+func LuceneRange(field string, from string, to string, inclusive bool) Lucene {
+	lo, hi := "{", "}"
+	if inclusive {
+		lo, hi = "[", "]"
+	}
+	return Lucene(LuceneEscape(field) + ":" + lo + LuceneEscape(from) + " TO " + LuceneEscape(to) + hi)
+}
+
+/*
+LuceneAnd(q ...Lucene) returns a query matching when every q matches
+*/
+func LuceneAnd(q ...Lucene) Lucene {
+	return luceneJoin(" AND ", q)
+}
+
+/*
+LuceneOr(q ...Lucene) returns a query matching when any q matches
+*/
+func LuceneOr(q ...Lucene) Lucene {
+	return luceneJoin(" OR ", q)
+}
+
+/*
+LuceneNot(q Lucene) returns q negated, lucene only accepts it combined with another query through LuceneAnd
+*/
+func LuceneNot(q Lucene) Lucene {
+	return Lucene("NOT (" + string(q) + ")")
+}
+
+// joins the queries with op, wrapping the whole in parenthesis so it nests
+func luceneJoin(op string, q []Lucene) Lucene {
+	switch len(q) {
+	case 0:
+		return ""
+	case 1:
+		return q[0]
+	}
+	parts := make([]string, len(q))
+	for i, p := range q {
+		parts[i] = string(p)
+	}
+	return Lucene("(" + strings.Join(parts, op) + ")")
+}
+
+/*
+SearchLucene(q Lucene, category string, index type string, order string) returns array of NeoTemplate structs and any errors raised as error
+see SearchIdxOrdered for the values of order
+*/
+func (this *Neo4j) SearchLucene(q Lucene, cat string, idxType string, order string) ([]*NeoTemplate, error) {
+	return this.SearchIdxOrdered("", "", string(q), cat, idxType, order)
+}
+
+// encodes a query for the query string of an index search
+func queryEscape(s string) string {
+	return url.QueryEscape(s)
+}
+
+// encodes a key or value for a path segment of an index url, neo4j.go shadows net/url with its url variables
+func pathEscape(s string) string {
+	return url.PathEscape(s)
+}
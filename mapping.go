@@ -0,0 +1,520 @@
+package neo4j
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// implemented by structs whose label isn't their type name, see Label
+type Labeler interface {
+	Label() string
+}
+
+// a struct field mapped to a node property
+type entityField struct {
+	Index     []int     // see reflect.Value.FieldByIndex
+	Name      string    // property name
+	OmitEmpty bool      // leave the property out when the field holds its zero value
+	ID        bool      // the field holds the node id instead of a property
+	Rel       Direction // set on relationship fields, Name then holds the relationship type
+	Version   bool      // the field holds the version Save checks and increments, see ErrVersionConflict
+}
+
+// returned by Save when the node was updated by someone else since the entity was loaded, matches ErrConflict with errors.Is
+var ErrVersionConflict error = &wrapError{"Entity was changed since it was loaded.", ErrConflict}
+
+/*
+Label(entity interface{}) returns the label nodes of entity carry
+the name of the struct type unless entity implements Labeler
+*/
+func Label(entity interface{}) string {
+	if l, ok := entity.(Labeler); ok {
+		return l.Label()
+	}
+	t := reflect.TypeOf(entity)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil {
+		return ""
+	}
+	return t.Name()
+}
+
+/*
+Marshal(entity interface{}) returns the label and properties of the node entity maps to and any errors raised as error
+entity is a struct or pointer to one, exported fields become properties named by their neo4j tag or else the field name:
+
+	type Person struct {
+		ID      neo4j.NodeID `neo4j:",id"`           // node id, not stored as a property
+		Name    string       `neo4j:"name"`
+		Email   string       `neo4j:"email,omitempty"` // not stored when blank
+		Secret  string       `neo4j:"-"`               // never stored
+		Friends []*Person    `neo4j:"KNOWS,out"`       // relationships, see Save and Load
+		Version int64        `neo4j:"_version,version"` // opt in optimistic locking, see Save
+	}
+*/
+func (this *Neo4j) Marshal(entity interface{}) (label string, props map[string]interface{}, err error) {
+	v, err := structValue(entity)
+	if err != nil {
+		return "", nil, err
+	}
+	props = map[string]interface{}{}
+	for _, f := range entityFields(v.Type()) {
+		if f.ID || len(f.Rel) > 0 {
+			continue
+		}
+		fv := v.FieldByIndex(f.Index)
+		if f.OmitEmpty && fv.IsZero() {
+			continue
+		}
+		props[f.Name] = fv.Interface()
+	}
+	return Label(entity), props, nil
+}
+
+/*
+Unmarshal(tmpl *NeoTemplate, entity interface{}) returns any errors raised as error
+fills the struct entity points to from the node in tmpl, fields are matched to properties the same way as Marshal
+property values are converted with FromNeo, so registered converters apply. with Flatten set, flattened properties are nested back into map fields
+*/
+func (this *Neo4j) Unmarshal(tmpl *NeoTemplate, entity interface{}) error {
+	if tmpl == nil {
+		return errors.New("Node must not be nil.")
+	}
+	ptr := reflect.ValueOf(entity)
+	if ptr.Kind() != reflect.Ptr || ptr.IsNil() || ptr.Elem().Kind() != reflect.Struct {
+		return errors.New("Entity must be a non nil pointer to a struct.")
+	}
+	v := ptr.Elem()
+	data := tmpl.Data
+	if len(this.Flatten) > 0 { // map fields were stored flattened
+		data = UnflattenProperties(data, this.Flatten)
+	}
+	for _, f := range entityFields(v.Type()) {
+		fv := v.FieldByIndex(f.Index)
+		if f.ID {
+			err := assignValue(fv, tmpl.ID)
+			if err != nil {
+				return errors.New("Field " + f.Name + ": " + err.Error())
+			}
+			continue
+		}
+		if len(f.Rel) > 0 {
+			continue
+		}
+		value, ok := data[f.Name]
+		if !ok {
+			continue
+		}
+		err := this.FromNeo(value, fv.Addr().Interface())
+		if err != nil {
+			return errors.New("Property " + f.Name + ": " + err.Error())
+		}
+	}
+	return nil
+}
+
+// dereferences entity down to the struct it holds
+func structValue(entity interface{}) (reflect.Value, error) {
+	v := reflect.ValueOf(entity)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return v, errors.New("Entity must not be nil.")
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return v, errors.New("Entity must be a struct or pointer to a struct.")
+	}
+	return v, nil
+}
+
+// lists the mapped fields of struct type t, fields of embedded structs are included as if they were t's own
+func entityFields(t reflect.Type) []*entityField {
+	fields := []*entityField{}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("neo4j")
+		if tag == "-" {
+			continue
+		}
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct && len(tag) < 1 {
+			for _, f := range entityFields(sf.Type) {
+				f.Index = append([]int{i}, f.Index...)
+				fields = append(fields, f)
+			}
+			continue
+		}
+		if len(sf.PkgPath) > 0 { // unexported
+			continue
+		}
+		opts := strings.Split(tag, ",")
+		f := &entityField{Index: []int{i}, Name: strings.TrimSpace(opts[0])}
+		if len(f.Name) < 1 {
+			f.Name = sf.Name
+		}
+		for _, o := range opts[1:] {
+			switch strings.TrimSpace(o) {
+			case "omitempty":
+				f.OmitEmpty = true
+			case "id":
+				f.ID = true
+			case "out":
+				f.Rel = DirOut
+			case "in":
+				f.Rel = DirIn
+			case "version":
+				f.Version = true
+			}
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+/*
+Save(entity interface{}) returns any errors raised as error
+creates the node entity points to, or updates its properties when the id field is already set, then saves the entities held in its relationship fields the same way
+relationships missing between them are created, existing ones are left alone. properties and relationships not mapped by entity are never removed
+entity must have a field tagged neo4j:",id", a zero id means the node doesn't exist yet
+when entity has a field tagged neo4j:"_version,version" the update only goes through if the stored version still equals the field,
+the version is incremented along with it, otherwise ErrVersionConflict is returned and nothing is written
+*/
+func (this *Neo4j) Save(entity interface{}) error {
+	ptr := reflect.ValueOf(entity)
+	if ptr.Kind() != reflect.Ptr || ptr.IsNil() || ptr.Elem().Kind() != reflect.Struct {
+		return errors.New("Entity must be a non nil pointer to a struct.")
+	}
+	_, err := this.save(ptr, map[uintptr]NodeID{})
+	return err
+}
+
+// saves the entity ptr points to and its relationships, seen holds the entities saved already so cycles end
+func (this *Neo4j) save(ptr reflect.Value, seen map[uintptr]NodeID) (NodeID, error) {
+	if id, ok := seen[ptr.Pointer()]; ok {
+		return id, nil
+	}
+	v := ptr.Elem()
+	fields := entityFields(v.Type())
+	var idField, versionField *entityField
+	for _, f := range fields {
+		if f.ID {
+			idField = f
+		}
+		if f.Version {
+			versionField = f
+		}
+	}
+	if idField == nil {
+		return 0, errors.New("Entity " + v.Type().Name() + " has no field tagged neo4j:\",id\".")
+	}
+	label, props, err := this.Marshal(ptr.Interface())
+	if err != nil {
+		return 0, err
+	}
+	idValue := v.FieldByIndex(idField.Index)
+	id := NodeID(0)
+	if idValue.IsZero() {
+		err = this.validateLabels([]string{label}, props) // CreateNodeTyped only knows about the global validators
+		if err != nil {
+			return 0, err
+		}
+		if versionField != nil {
+			props[versionField.Name] = 1
+		}
+		uuid, err := this.assignUUID(props) // here rather than in CreateNodeTyped so the entity gets it too
+		if err != nil {
+			return 0, err
+		}
+		node, err := this.createLabeledNode("Neo4j.Save", label, props)
+		if err != nil {
+			return 0, err
+		}
+		id = node.NodeID()
+		err = assignValue(idValue, uint64(id))
+		if err != nil {
+			return 0, errors.New("Field " + idField.Name + ": " + err.Error())
+		}
+		for _, f := range fields {
+			if len(uuid) > 0 && f.Name == this.UUIDProperty && !f.ID && len(f.Rel) < 1 {
+				err = this.FromNeo(uuid, v.FieldByIndex(f.Index).Addr().Interface())
+				if err != nil {
+					return 0, errors.New("Field " + f.Name + ": " + err.Error())
+				}
+			}
+		}
+		if versionField != nil {
+			err = assignValue(v.FieldByIndex(versionField.Index), int64(1))
+			if err != nil {
+				return 0, errors.New("Field " + versionField.Name + ": " + err.Error())
+			}
+		}
+	} else if versionField != nil {
+		id = NodeID(idValue.Convert(reflect.TypeOf(uint64(0))).Uint())
+		err = this.validateNode(id, props)
+		if err != nil {
+			return 0, err
+		}
+		err = this.saveVersioned("Neo4j.Save", id, props, v.FieldByIndex(versionField.Index), versionField.Name)
+		if err != nil {
+			return 0, err
+		}
+	} else {
+		id = NodeID(idValue.Convert(reflect.TypeOf(uint64(0))).Uint())
+		err = this.SetPropertyTyped(id, props, false)
+		if err != nil {
+			return 0, err
+		}
+	}
+	seen[ptr.Pointer()] = id
+	for _, f := range fields {
+		if len(f.Rel) < 1 {
+			continue
+		}
+		for _, other := range relatedEntities(v.FieldByIndex(f.Index)) {
+			otherID, err := this.save(other, seen)
+			if err != nil {
+				return 0, err
+			}
+			src, dst := id, otherID
+			if f.Rel == DirIn {
+				src, dst = otherID, id
+			}
+			_, _, err = this.CreateUniqueRelationship(src, dst, nil, f.Name)
+			if err != nil {
+				return 0, err
+			}
+		}
+	}
+	return id, nil
+}
+
+// creates a node carrying label in a single cypher CREATE, so there is never a node without its label. runs the global validators like CreateNodeTyped
+func (this *Neo4j) createLabeledNode(op string, label string, props map[string]interface{}) (*NeoTemplate, error) {
+	if len(label) < 1 {
+		return this.CreateNodeTyped(props)
+	}
+	err := this.validate(props)
+	if err != nil {
+		return nil, err
+	}
+	props, err = this.encodeProperties(props)
+	if err != nil {
+		return nil, err
+	}
+	props, err = this.newNodeProperties(props)
+	if err != nil {
+		return nil, err
+	}
+	result, err := this.cypher(op, "CREATE (n:"+this.cypherName(label)+" {props}) RETURN n", map[string]interface{}{"props": props})
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Data) < 1 || len(result.Data[0]) < 1 {
+		return nil, errors.New("Create returned no node.")
+	}
+	return this.cypherTemplate(result.Data[0][0])
+}
+
+// sets props on the node when its version property still equals the version field, then increments both
+func (this *Neo4j) saveVersioned(op string, id NodeID, props map[string]interface{}, version reflect.Value, name string) error {
+	delete(props, name)
+	props, err := this.encodeProperties(props)
+	if err != nil {
+		return err
+	}
+	current := int64(0)
+	err = assignValue(reflect.ValueOf(&current).Elem(), version.Interface())
+	if err != nil {
+		return errors.New("Field " + name + ": " + err.Error())
+	}
+	prop := "n." + this.cypherName(name)
+	query := "START n=node({id}) SET n.`_lock_` = true REMOVE n.`_lock_` " + // write lock before reading the version
+		"WITH n WHERE coalesce(" + prop + ", 0) = {version} " +
+		"SET n += {props}, " + prop + " = {version} + 1 RETURN " + prop
+	result, err := this.cypher(op, query, map[string]interface{}{"id": id, "version": current, "props": props})
+	if err != nil {
+		return err
+	}
+	if len(result.Data) < 1 {
+		return ErrVersionConflict
+	}
+	return assignValue(version, current+1)
+}
+
+// pointers to the entities held in a relationship field: a struct, pointer to one or slice of either
+func relatedEntities(fv reflect.Value) []reflect.Value {
+	list := []reflect.Value{}
+	add := func(e reflect.Value) {
+		switch {
+		case e.Kind() == reflect.Ptr && !e.IsNil() && e.Elem().Kind() == reflect.Struct:
+			list = append(list, e)
+		case e.Kind() == reflect.Struct && e.CanAddr():
+			list = append(list, e.Addr())
+		}
+	}
+	if fv.Kind() == reflect.Slice {
+		for i := 0; i < fv.Len(); i++ {
+			add(fv.Index(i))
+		}
+	} else {
+		add(fv)
+	}
+	return list
+}
+
+/*
+Load(node id uint, entity interface{}) returns any errors raised as error
+fills the struct entity points to from the node like Unmarshal, then fills its relationship fields with the nodes at the other end
+related entities are loaded one level deep, their own relationship fields are left empty
+*/
+func (this *Neo4j) Load(id NodeID, entity interface{}) error {
+	node, err := this.GetNode(id)
+	if err != nil {
+		return err
+	}
+	err = this.Unmarshal(node, entity)
+	if err != nil {
+		return err
+	}
+	v := reflect.ValueOf(entity).Elem()
+	for _, f := range entityFields(v.Type()) {
+		if len(f.Rel) < 1 {
+			continue
+		}
+		rels, err := this.GetRelationshipsOfTypes(id, f.Rel, f.Name)
+		if err != nil {
+			return err
+		}
+		fv := v.FieldByIndex(f.Index)
+		fv.Set(reflect.Zero(fv.Type()))
+		for _, r := range rels {
+			end := r.End
+			if f.Rel == DirIn {
+				end = r.Start
+			}
+			otherID, err := idFromURL(end)
+			if err != nil {
+				return err
+			}
+			other, err := this.GetNode(NodeID(otherID))
+			if err != nil {
+				return err
+			}
+			err = this.setRelated(fv, other)
+			if err != nil {
+				return errors.New("Field " + f.Name + ": " + err.Error())
+			}
+			if fv.Kind() != reflect.Slice {
+				break // a single entity field only takes the first
+			}
+		}
+	}
+	return nil
+}
+
+// unmarshals node into a new entity of the element type of relationship field fv and stores it there
+func (this *Neo4j) setRelated(fv reflect.Value, node *NeoTemplate) error {
+	t := fv.Type()
+	if t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	st := t
+	if st.Kind() == reflect.Ptr {
+		st = st.Elem()
+	}
+	if st.Kind() != reflect.Struct {
+		return errors.New("Relationship fields must hold structs.")
+	}
+	e := reflect.New(st)
+	err := this.Unmarshal(node, e.Interface())
+	if err != nil {
+		return err
+	}
+	if t.Kind() != reflect.Ptr {
+		e = e.Elem()
+	}
+	if fv.Kind() == reflect.Slice {
+		fv.Set(reflect.Append(fv, e))
+	} else {
+		fv.Set(e)
+	}
+	return nil
+}
+
+/*
+RegisterModel(label string, sample interface{})
+associates label with the struct type of sample so Decode knows what to turn nodes carrying label into, ie: RegisterModel("Person", Person{})
+register models before the client is shared between goroutines
+*/
+func (this *Neo4j) RegisterModel(label string, sample interface{}) {
+	t := reflect.TypeOf(sample)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if this.models == nil {
+		this.models = map[string]reflect.Type{}
+	}
+	this.models[label] = t
+}
+
+/*
+Decode(tmpl *NeoTemplate) returns a pointer to a new struct of the model registered for the labels of the node and any errors raised as error
+the first label of the node with a registered model wins. labels are looked up on the server when tmpl doesn't carry them
+*/
+func (this *Neo4j) Decode(tmpl *NeoTemplate) (interface{}, error) {
+	if tmpl == nil {
+		return nil, errors.New("Node must not be nil.")
+	}
+	labels := tmpl.Labels
+	if labels == nil {
+		var err error
+		labels, err = this.GetLabels(tmpl.NodeID())
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, label := range labels {
+		t, ok := this.models[label]
+		if !ok || t.Kind() != reflect.Struct {
+			continue
+		}
+		entity := reflect.New(t).Interface()
+		err := this.Unmarshal(tmpl, entity)
+		if err != nil {
+			return nil, err
+		}
+		return entity, nil
+	}
+	return nil, errors.New("No model registered for labels [" + strings.Join(labels, ", ") + "].")
+}
+
+/*
+DecodeColumn(result *CypherResult, column string) returns the nodes in column decoded with Decode, one per row, and any errors raised as error
+*/
+func (this *Neo4j) DecodeColumn(result *CypherResult, column string) ([]interface{}, error) {
+	col := -1
+	for i, c := range result.Columns {
+		if c == column {
+			col = i
+		}
+	}
+	if col < 0 {
+		return nil, errors.New("Column " + column + " not in result.")
+	}
+	list := make([]interface{}, 0, len(result.Data))
+	for _, row := range result.Data {
+		node, err := this.cypherTemplate(row[col])
+		if err != nil {
+			return nil, err
+		}
+		entity, err := this.Decode(node)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, entity)
+	}
+	return list, nil
+}
@@ -0,0 +1,38 @@
+package neo4j
+
+import (
+	"time"
+)
+
+// receives what the client measures, see Instrument. bind it to prometheus or whatever the application uses
+// called from the goroutines sending the requests, implementations have to be safe for concurrent use
+type Metrics interface {
+	Request(op string, method string, status int, d time.Duration) // a request got its response, status is 0 when it failed without one. op is the client method, ie: Neo4j.GetNode
+	Retry(op string, method string)                                // a request or transaction is sent again, see Retry & Transact
+	Transactions(delta int)                                        // +1 when a batch transaction is sent, -1 once it finished, the sum is the number open
+}
+
+/*
+Instrument(m Metrics) returns an Option reporting every request, retry and batch transaction to m
+ie: a Request implementation observing d in a prometheus.HistogramVec labeled by op and status gives counts and latency in one
+*/
+func Instrument(m Metrics) Option {
+	return func(neo *Neo4j) error {
+		neo.metrics = m
+		return nil
+	}
+}
+
+// reports a finished request when the client is instrumented
+func (this *Neo4j) observe(op string, method string, status int, start time.Time) {
+	if this.metrics != nil {
+		this.metrics.Request(op, method, status, time.Since(start))
+	}
+}
+
+// reports a retry when the client is instrumented
+func (this *Neo4j) observeRetry(op string, method string) {
+	if this.metrics != nil {
+		this.metrics.Retry(op, method)
+	}
+}
@@ -24,21 +24,48 @@ package neo4j
 
 import (
 	"net/http"
-	"log"
+	"fmt"
 	"errors"
 	"encoding/json"
 	"strings"
 	"bytes"
 	"strconv"
+	"reflect"
+	"io"
+	"context"
+	"time"
+	"log/slog"
 )
 
 // general neo4j config
+// safe for concurrent use once configured: every request carries its own method and status code
 type Neo4j struct {
-	Method     string // which http method
-	StatusCode int    // last http status code received
-	URL        string
-	Username   string
-	Password   string
+	TimeFormat   TimeFormat   // how time.Time property values are stored
+	BlobMarker   string       // prefix marking base64 encoded []byte property values, defaults to "base64:"
+	Flatten      string       // when set nested maps in property values are stored flattened, keys joined by it. ie: "." stores address.city. GetProperties, GetPropertyValues and Unmarshal nest them back, other NeoTemplate.Data holds the flat keys
+	UUIDProperty string       // when set every node created gets a random UUID in this property, see UseUUIDs
+	Client       *http.Client // sends every request, copies of the client share it so connections are pooled
+	URL          string
+	Username     string
+	Password     string
+	Token        string                      // authenticates instead of Username & Password when set, see AuthToken
+	converters   map[reflect.Type]*Converter // see RegisterConverter
+	models       map[string]reflect.Type     // see RegisterModel
+	validators   map[string][]Validator      // see RegisterValidator
+	ctx          context.Context             // see WithContext
+	timeout      time.Duration               // see WithTimeout
+	noGzip       bool                        // see Compression
+	stream       bool                        // see Streaming
+	endpoints    map[string]string           // urls advertised in the service root, see endpoint
+	retry        *retryPolicy                // see Retry
+	breaker      *breaker                    // see CircuitBreaker, shared by copies of the client
+	limiter      Limiter                     // see Limit
+	statusErrors map[int]error               // see MapStatus, never changed once set so copies can share it
+	deadlock     *retryPolicy                // see DeadlockRetry
+	logger       *slog.Logger                // see Logger
+	debug        bool                        // see Debug
+	metrics      Metrics                     // see Instrument
+	rotated      *rotatedAuth                // see ChangePassword, shared by copies of the client
 }
 type Error struct {
 	List map[int]error
@@ -65,11 +92,79 @@ type NeoTemplate struct {
 	Length              string        // traverse framework
 	Nodes               []interface{} // traverse framework
 	TRelationships      []interface{} // traverse framework
+	Score               float64       // index search hits, when ordered by score/relevance
+	Labels              []string      // node labels, only sent along by servers that include node metadata
+	Weight              float64       // total cost of a path found by a weighted algorithm
+}
+// direction of relationships relative to a node
+type Direction string
+
+const (
+	DirIn  Direction = "in"
+	DirOut Direction = "out"
+	DirAll Direction = "all"
+)
+// id of a node, a distinct type from RelID so the two can't be mixed up
+type NodeID uint64
+// id of a relationship
+type RelID uint64
+// used when storing relationship data returned from neo4j
+type Relationship struct {
+	ID         RelID
+	StartID    NodeID // parsed from Start
+	EndID      NodeID // parsed from End
+	Type       string
+	Data       map[string]interface{}
+	Self       string
+	Start      string
+	End        string
+	Properties string
+}
+/*
+WithContext(ctx context.Context) returns a copy of the client sending its requests with ctx
+cancelling ctx or passing its deadline aborts the requests in flight, ie: neo.WithContext(ctx).TraversalFrom(id)...
+*/
+func (this *Neo4j) WithContext(ctx context.Context) *Neo4j {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	neo := *this
+	neo.ctx = ctx
+	return &neo
+}
+/*
+Context() returns the context requests are sent with, context.Background() unless set with WithContext
+*/
+func (this *Neo4j) Context() context.Context {
+	if this.ctx == nil {
+		return context.Background()
+	}
+	return this.ctx
+}
+// used by clients without an http.Client of their own
+var defaultClient = newHTTPClient()
+
+// idle connections kept per host, the net/http default of 2 is too few once Bulk runs requests in parallel
+const maxIdleConnsPerHost = 64
+
+// builds an http.Client with its own connection pool, proxies are taken from the environment like net/http does by default
+func newHTTPClient() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = maxIdleConnsPerHost
+	return &http.Client{Transport: transport}
 }
 // what chars to escape of course
 const escapedChars = `&'<>"*[]:% `
 
-func NewNeo4j(u string, user string, passwd string) (*Neo4j, error) {
+// digits of a percent-encoded byte
+const upperHex = "0123456789ABCDEF"
+/*
+NewNeo4j(url string, user string, password string, options ...Option) returns a Neo4j client and any errors raised as error
+url defaults to http://127.0.0.1:7474/db/data, options like Timeout are applied before the connection is tested
+when user or password are given every request authenticates with them using HTTP Basic auth, as neo4j 2.2 and later require
+the node, index, batch, cypher, label and schema urls the service root advertises are used instead of paths under url, so servers mounted elsewhere work too
+*/
+func NewNeo4j(u string, user string, passwd string, opts ...Option) (*Neo4j, error) {
 	n := new(Neo4j)
 	if len(u) < 1 {
 		u = "http://127.0.0.1:7474/db/data"
@@ -82,22 +177,95 @@ func NewNeo4j(u string, user string, passwd string) (*Neo4j, error) {
         }
 
 	n.URL = u
-	_, err := n.send(u, "") // just a test to see if the connection is valid
-	return n, err
+	n.Client = newHTTPClient()
+	n.rotated = new(rotatedAuth)
+	for _, opt := range opts {
+		err := opt(n)
+		if err != nil {
+			return n, err
+		}
+	}
+	body, _, err := n.send("NewNeo4j", "GET", u, "") // tests the connection and reads the service root
+	if err != nil {
+		return n, err
+	}
+	n.discover(body)
+	return n, nil
+}
+/*
+GetReferenceNode() returns a NeoTemplate struct of the reference node and any errors raised as error
+only 1.x servers advertise a reference node in the service root
+*/
+func (this *Neo4j) GetReferenceNode() (tmp *NeoTemplate, err error) {
+	root, err := this.serviceRoot("Neo4j.GetReferenceNode")
+	if err != nil {
+		return tmp, err
+	}
+	ref, _ := root["reference_node"].(string)
+	if len(ref) < 1 {
+		return tmp, errors.New("Server has no reference node.")
+	}
+	errorList := map[int]error{
+		404: errors.New("Node not found."),
+	}
+	return this.receiveTemplate("Neo4j.GetReferenceNode", "GET", ref, "", errorList) // can't go through GetNode, the reference node has id 0
+}
+// fetches the service root document which lists the urls of everything the server offers
+func (this *Neo4j) serviceRoot(op string) (map[string]interface{}, error) {
+	body, resp, err := this.send(op, "GET", this.URL, "")
+	if err != nil {
+		return nil, err
+	}
+	err = this.statusError(op, map[int]error{}, resp, body)
+	if err != nil {
+		return nil, err
+	}
+	root := map[string]interface{}{}
+	err = json.Unmarshal([]byte(body), &root)
+	if err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+// remembers the urls the service root advertises, a body that isn't a service root leaves the defaults in place
+func (this *Neo4j) discover(body string) {
+	root := map[string]interface{}{}
+	if json.Unmarshal([]byte(body), &root) != nil {
+		return
+	}
+	this.endpoints = map[string]string{}
+	for name, v := range root {
+		if u, ok := v.(string); ok && strings.HasPrefix(u, "http") {
+			this.endpoints[name] = strings.TrimSuffix(u, "/")
+		}
+	}
+}
+// url of the endpoint the service root advertises under name, URL+path when it wasn't advertised
+func (this *Neo4j) endpoint(name string, path string) string {
+	if u, ok := this.endpoints[name]; ok {
+		return u
+	}
+	return this.URL + path
+}
+// base url of the node or relationship index
+func (this *Neo4j) indexURL(idxType string) string {
+	if strings.ToLower(idxType) == "relationship" {
+		return this.endpoint("relationship_index", "/index/relationship")
+	}
+	return this.endpoint("node_index", "/index/node")
 }
 /*
 GetProperty(node id uint, name string) returns string of property value and any error raised as error
 */
-func (this *Neo4j) GetProperty(id uint64, name string) (string, error) {
+func (this *Neo4j) GetProperty(id NodeID, name string) (string, error) {
 	if len(name) < 1 {
 		return "", errors.New("Property name must be at least 1 character.")
 	}
-	node, err := this.GetNode(id) // find properties for node
+	node, err := this.nodeURLs(id)
 	if err != nil {
 		return "", err
 	}
-	this.Method = "get"
-	body, err := this.send(node.Properties+"/"+name, "")
+	body, resp, err := this.send("Neo4j.GetProperty", "GET", node.Properties+"/"+name, "")
 	if err != nil {
 		return "", err
 	}
@@ -105,21 +273,29 @@ func (this *Neo4j) GetProperty(id uint64, name string) (string, error) {
 		404: errors.New("Node or Property not found."),
 		204: errors.New("No properties found."),
 	}
-	return body, this.NewError(errorList)
+	return body, this.statusError("Neo4j.GetProperty", errorList, resp, body)
 }
 /*
 GetProperties(node id uint)  returns a NeoTemplate struct and any errors raised as error
+with Flatten set, flattened properties come back nested like they were written
 */
-func (this *Neo4j) GetProperties(id uint64) (tmp *NeoTemplate, err error) {
-	node, err := this.GetNode(id) // find properties for node
+func (this *Neo4j) GetProperties(id NodeID) (tmp *NeoTemplate, err error) {
+	node, err := this.nodeURLs(id)
 	if err != nil {
 		return tmp, err
 	}
-	this.Method = "get"
-	body, err := this.send(node.Properties, "")
+	body, resp, err := this.send("Neo4j.GetProperties", "GET", node.Properties, "")
 	if err != nil {
 		return tmp, err
 	}
+	errorList := map[int]error{
+		404: errors.New("Node or Property not found."),
+		204: errors.New("No properties found."),
+	}
+	err = this.statusError("Neo4j.GetProperties", errorList, resp, body)
+	if err != nil || this.ignored(resp.StatusCode) {
+		return tmp, err
+	}
 	// pack json string into variable "data" so the json unmarshaler knows where to put it on struct type NeoTemplate
 	jsonData, err := this.pack("data", body)
 	if err != nil {
@@ -130,40 +306,47 @@ func (this *Neo4j) GetProperties(id uint64) (tmp *NeoTemplate, err error) {
 	if err != nil {
 		return tmp, err
 	}
-	errorList := map[int]error{
-		404: errors.New("Node or Property not found."),
-		204: errors.New("No properties found."),
+	if len(this.Flatten) > 0 {
+		template[0].Data = UnflattenProperties(template[0].Data, this.Flatten)
 	}
-	return template[0], this.NewError(errorList)
+	return template[0], nil
 }
 /*
 SetProperty(node id uint, data map[string]string, replace bool) returns any error raised as error
 typically replace should be false unless you wish to drop any other properties *not* specified in the data you sent to SetProperty
 */
-func (this *Neo4j) SetProperty(id uint64, data map[string]string, replace bool) error {
-	node, err := this.GetNode(id) // find properties for node
+func (this *Neo4j) SetProperty(id NodeID, data map[string]string, replace bool) error {
+	return this.SetPropertyTyped(id, typedProperties(data), replace)
+}
+/*
+SetPropertyTyped(node id uint, data map[string]interface{}, replace bool) returns any error raised as error
+same as SetProperty but values keep their json type, so numbers, booleans and arrays aren't stored as strings
+a nil value deletes the property, the updates and deletes are applied together in a single request
+*/
+func (this *Neo4j) SetPropertyTyped(id NodeID, data map[string]interface{}, replace bool) error {
+	node, err := this.nodeURLs(id)
 	if err != nil {
 		return err
 	}
-	this.Method = "put"
-	s, err := json.Marshal(data)
+	err = this.validateNode(id, data)
 	if err != nil {
 		return err
 	}
-	if replace { // drop all properties on the node if they aren't specified in "data" ?
-		_, err := this.send(node.Properties, string(s))
-		if err != nil {
-			return err
-		}
-	} else {
-		for k, v := range data {
-			k = strings.TrimSpace(k)                                     // strip leading & trailing whitespace from key
-			_, err := this.send(node.Properties+"/"+k, strconv.Quote(v)) // wrap value in double quotes as neo4j expects
-			if err != nil {
-				return err
-			}
+	data, err = this.encodeProperties(data) // hands back a copy, safe to delete from
+	if err != nil {
+		return err
+	}
+	removed := []string{}
+	for k, v := range data {
+		if v == nil {
+			removed = append(removed, strings.TrimSpace(k))
+			delete(data, k)
 		}
 	}
+	if len(removed) > 0 && !replace { // when replacing, leaving the key out of data drops it already
+		return this.setAndRemove("Neo4j.SetPropertyTyped", id, data, removed)
+	}
+	s, err := json.Marshal(data)
 	if err != nil {
 		return err
 	}
@@ -171,14 +354,40 @@ func (this *Neo4j) SetProperty(id uint64, data map[string]string, replace bool)
 		404: errors.New("Node not found."),
 		400: errors.New("Invalid data sent."),
 	}
-	return this.NewError(errorList)
+	if replace { // drop all properties on the node if they aren't specified in "data" ?
+		body, resp, err := this.send("Neo4j.SetPropertyTyped", "PUT", node.Properties, string(s))
+		if err != nil {
+			return err
+		}
+		return this.statusError("Neo4j.SetPropertyTyped", errorList, resp, body)
+	}
+	for k, v := range data {
+		k = strings.TrimSpace(k) // strip leading & trailing whitespace from key
+		value, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		body, resp, err := this.send("Neo4j.SetPropertyTyped", "PUT", node.Properties+"/"+k, string(value))
+		if err != nil {
+			return err
+		}
+		err = this.statusError("Neo4j.SetPropertyTyped", errorList, resp, body)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 /*
 CreateProperty(node id uint, data map[string]string, replace bool) returns any errors raised as error
 typically replace should be false unless you wish to drop any other properties *not* specified in the data you sent to CreateProperty
 */
-func (this *Neo4j) CreateProperty(id uint64, data map[string]string, replace bool) error {
-	node, err := this.GetNode(id) // find properties for node
+func (this *Neo4j) CreateProperty(id NodeID, data map[string]string, replace bool) error {
+	node, err := this.nodeURLs(id)
+	if err != nil {
+		return err
+	}
+	err = this.validateNode(id, typedProperties(data))
 	if err != nil {
 		return err
 	}
@@ -186,57 +395,58 @@ func (this *Neo4j) CreateProperty(id uint64, data map[string]string, replace boo
 	if err != nil {
 		return err
 	}
-	this.Method = "put"
+	errorList := map[int]error{
+		404: errors.New("Node or Property not found."),
+		400: errors.New("Invalid data sent."),
+	}
 	if replace { // when replacing and dropping *ALL* values on node(not just new ones) we can simply pass in the entire json data set and neo4j will remove the old properties
-		_, err := this.send(node.Properties, string(s))
+		body, resp, err := this.send("Neo4j.CreateProperty", "PUT", node.Properties, string(s))
 		if err != nil {
 			return err
 		}
-	} else { // if we are keeping the other properties on the node we must pass in new properties 1 at a time
-		for k, v := range data {
-			k = strings.TrimSpace(k)                                     // strip leading & trailing whitespace from key
-			_, err := this.send(node.Properties+"/"+k, strconv.Quote(v)) // wrap value in double quotes as neo4j expects
-			if err != nil {
-				return err
-			}
-		}
+		return this.statusError("Neo4j.CreateProperty", errorList, resp, body)
 	}
-	errorList := map[int]error{
-		404: errors.New("Node or Property not found."),
-		400: errors.New("Invalid data sent."),
+	for k, v := range data { // if we are keeping the other properties on the node we must pass in new properties 1 at a time
+		k = strings.TrimSpace(k)                                                  // strip leading & trailing whitespace from key
+		body, resp, err := this.send("Neo4j.CreateProperty", "PUT", node.Properties+"/"+k, strconv.Quote(v)) // wrap value in double quotes as neo4j expects
+		if err != nil {
+			return err
+		}
+		err = this.statusError("Neo4j.CreateProperty", errorList, resp, body)
+		if err != nil {
+			return err
+		}
 	}
-	return this.NewError(errorList)
+	return nil
 }
 /*
 DelProperty(node id uint, s string) returns any errors raised as error
 pass in the id of the node and string as the the name/key of the property to delete
-could be extended to also delete relationship properties as well
+see DelRelationshipProperty for relationships
 */
-func (this *Neo4j) DelProperty(id uint64, s string) error {
-	node, err := this.GetNode(id) // find properties for node
+func (this *Neo4j) DelProperty(id NodeID, s string) error {
+	node, err := this.nodeURLs(id)
 	if err != nil {
 		return err
 	}
-	this.Method = "delete"
-	_, err = this.send(node.Properties+"/"+string(s), "")
+	body, resp, err := this.send("Neo4j.DelProperty", "DELETE", node.Properties+"/"+string(s), "")
 	if err != nil {
 		return err
 	}
 	errorList := map[int]error{
 		404: errors.New("Node or Property not found."),
 	}
-	return this.NewError(errorList)
+	return this.statusError("Neo4j.DelProperty", errorList, resp, body)
 }
 /*
 DelNode(node id uint) returns any errors raised as error
 */
-func (this *Neo4j) DelNode(id uint64) error {
-	node, err := this.GetNode(id) // find properties for node
+func (this *Neo4j) DelNode(id NodeID) error {
+	node, err := this.nodeURLs(id)
 	if err != nil {
 		return err
 	}
-	this.Method = "delete"
-	_, err = this.send(node.Self, "")
+	body, resp, err := this.send("Neo4j.DelNode", "DELETE", node.Self, "")
 	if err != nil {
 		return err
 	}
@@ -244,320 +454,823 @@ func (this *Neo4j) DelNode(id uint64) error {
 		404: errors.New("Node not found."),
 		409: errors.New("Unable to delete node. May still have relationships."),
 	}
-	return this.NewError(errorList)
+	return this.statusError("Neo4j.DelNode", errorList, resp, body)
+}
+/*
+DelNodeForce(node id uint) returns any errors raised as error
+deletes every relationship on the node and then the node itself in a single batch, so either all of it goes or nothing does
+*/
+func (this *Neo4j) DelNodeForce(id NodeID) error {
+	rels, err := this.GetRelationshipsOnNode(id, "", DirAll)
+	if err != nil {
+		return err
+	}
+	session := this.NewSession()
+	for i := 0; i < len(rels); i++ {
+		session.Delete(session.Relationship(rels[i].RelID()))
+	}
+	session.Delete(session.Node(id))
+	_, err = session.flush("Neo4j.DelNodeForce")
+	return err
+}
+/*
+CreateNode(data map[string]string) returns a NeoTemplate struct and any errors raised as error
+*/
+func (this *Neo4j) CreateNode(data map[string]string) (tmp *NeoTemplate, err error) {
+	return this.CreateNodeTyped(typedProperties(data))
+}
+/*
+CreateNodeTyped(data map[string]interface{}) returns a NeoTemplate struct and any errors raised as error
+same as CreateNode but values keep their json type
+*/
+func (this *Neo4j) CreateNodeTyped(data map[string]interface{}) (tmp *NeoTemplate, err error) {
+	err = this.validate(data)
+	if err != nil {
+		return tmp, err
+	}
+	data, err = this.encodeProperties(data)
+	if err != nil {
+		return tmp, err
+	}
+	data, err = this.newNodeProperties(data)
+	if err != nil {
+		return tmp, err
+	}
+	s, err := marshal(data)
+	if err != nil {
+		return tmp, errors.New("Unable to Marshal Json data")
+	}
+	url := this.endpoint("node", "/node")
+	errorList := map[int]error{
+		400: errors.New("Invalid data sent."),
+	}
+	return this.receiveTemplate("Neo4j.CreateNodeTyped", "POST", url, s, errorList)
+}
+/*
+CreateNodeID(data map[string]string) returns the id of the new node and any errors raised as error
+reads the id off the Location header and never parses the response body, for loaders that don't need the NeoTemplate
+*/
+func (this *Neo4j) CreateNodeID(data map[string]string) (NodeID, error) {
+	err := this.validate(typedProperties(data))
+	if err != nil {
+		return 0, err
+	}
+	props, err := this.newNodeProperties(typedProperties(data))
+	if err != nil {
+		return 0, err
+	}
+	s, err := json.Marshal(props)
+	if err != nil {
+		return 0, errors.New("Unable to Marshal Json data")
+	}
+	resp, err := this.do("Neo4j.CreateNodeID", "POST", this.endpoint("node", "/node"), string(s))
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close() // only the Location header is needed
+	errorList := map[int]error{
+		400: errors.New("Invalid data sent."),
+	}
+	err = this.responseError("Neo4j.CreateNodeID", errorList, resp)
+	if err != nil {
+		return 0, err
+	}
+	id, err := idFromURL(resp.Header.Get("Location"))
+	return NodeID(id), err
+}
+// a NeoTemplate holding only the urls of node id, they follow from the id so there's no need to fetch the node for them
+func (this *Neo4j) nodeURLs(id NodeID) (*NeoTemplate, error) {
+	if id < 1 {
+		return nil, errors.New("Invalid node id specified.")
+	}
+	self := this.endpoint("node", "/node") + "/" + id.String()
+	return &NeoTemplate{
+		ID:                  uint64(id),
+		Self:                self,
+		Property:            self + "/properties/{key}",
+		Properties:          self + "/properties",
+		Relationships:       self + "/relationships",
+		RelationshipsOut:    self + "/relationships/out",
+		RelationshipsIn:     self + "/relationships/in",
+		RelationshipsAll:    self + "/relationships/all",
+		RelationshipsCreate: self + "/relationships",
+		Traverse:            self + "/traverse/{returnType}",
+	}, nil
+}
+/*
+GetNode(id uint) returns a NeoTemplate struct and any errors raised as error
+*/
+func (this *Neo4j) GetNode(id NodeID) (tmp *NeoTemplate, err error) {
+	if id < 1 {
+		return tmp, errors.New("Invalid node id specified.")
+	}
+	url := this.endpoint("node", "/node") + "/"
+	errorList := map[int]error{
+		404: errors.New("Node not found."),
+	}
+	return this.receiveTemplate("Neo4j.GetNode", "GET", url+strconv.FormatUint(uint64(id), 10), "", errorList) // convert uint -> string and send http request
+}
+/*
+GetNodes(node ids ...uint) returns a map of NeoTemplate structs keyed by node id and any errors raised as error
+fetches every node in a single batch request, the batch fails as a whole when any of the nodes is missing
+*/
+func (this *Neo4j) GetNodes(ids ...NodeID) (map[NodeID]*NeoTemplate, error) {
+	nodes := make(map[NodeID]*NeoTemplate)
+	if len(ids) < 1 {
+		return nodes, nil
+	}
+	jobs := make([]*BatchJob, len(ids))
+	for i, id := range ids {
+		jobs[i] = &BatchJob{Method: "GET", To: "/node/" + id.String(), ID: i}
+	}
+	results, err := this.batch("Neo4j.GetNodes", jobs)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range results {
+		if len(r.Data) > 0 {
+			nodes[r.Data[0].NodeID()] = r.Data[0]
+		}
+	}
+	return nodes, nil
+}
+/*
+NodeExists(node id uint) returns whether the node exists and any errors raised as error
+a missing node is not an error, the response body is never parsed
+*/
+func (this *Neo4j) NodeExists(id NodeID) (bool, error) {
+	if id < 1 {
+		return false, errors.New("Invalid node id specified.")
+	}
+	body, resp, err := this.send("Neo4j.NodeExists", "GET", this.endpoint("node", "/node")+"/"+id.String(), "")
+	if err != nil {
+		return false, err
+	}
+	if resp.StatusCode == 404 {
+		return false, nil
+	}
+	return resp.StatusCode == 200, this.statusError("Neo4j.NodeExists", map[int]error{}, resp, body)
+}
+/*
+GetRelationshipsOnNode(node id uint, name string, direction Direction) returns an array of NeoTemplate structs containing relationship data and any errors raised as error
+leave name blank to get the relationships of every type
+*/
+func (this *Neo4j) GetRelationshipsOnNode(id NodeID, name string, direction Direction) ([]*NeoTemplate, error) {
+	return this.GetRelationshipsOfTypes(id, direction, name)
+}
+/*
+GetRelationshipsOfTypes(node id uint, direction Direction, types ...string) returns an array of NeoTemplate structs containing relationship data and any errors raised as error
+fetches the relationships matching any of the types in a single request
+*/
+func (this *Neo4j) GetRelationshipsOfTypes(id NodeID, direction Direction, types ...string) ([]*NeoTemplate, error) {
+	direction, err := direction.check()
+	if err != nil {
+		return nil, err
+	}
+	node, err := this.nodeURLs(id)
+	if err != nil {
+		return nil, err
+	}
+	return this.relationshipsOf("Neo4j.GetRelationshipsOfTypes", node, direction, types...)
+}
+// fetches the relationships of a node fetched already, direction has been checked
+func (this *Neo4j) relationshipsOf(op string, node *NeoTemplate, direction Direction, types ...string) ([]*NeoTemplate, error) {
+	url := ""
+	switch direction {
+	case DirIn:
+		url = node.RelationshipsIn
+	case DirOut:
+		url = node.RelationshipsOut
+	default:
+		url = node.RelationshipsAll
+	}
+	names := []string{}
+	for _, t := range types {
+		if t = strings.TrimSpace(t); len(t) > 0 {
+			names = append(names, t)
+		}
+	}
+	if len(names) > 0 { // no types at all returns every relationship in direction
+		url += "/" + strings.Join(names, "&") // neo4j takes multiple types as TYPE1&TYPE2
+	}
+	errorList := map[int]error{
+		404: errors.New("Node not found."),
+	}
+	template, err := this.receiveTemplates(op, "GET", url, "", errorList)
+	if err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+/*
+GetDegree(node id uint, direction Direction, types ...string) returns the number of relationships on the node and any errors raised as error
+counts every type when none are passed
+*/
+func (this *Neo4j) GetDegree(id NodeID, direction Direction, types ...string) (int, error) {
+	if id < 1 {
+		return 0, errors.New("Invalid node id specified.")
+	}
+	direction, err := direction.check()
+	if err != nil {
+		return 0, err
+	}
+	url := this.endpoint("node", "/node") + "/" + id.String() + "/degree/" + string(direction)
+	names := []string{}
+	for _, t := range types {
+		if t = strings.TrimSpace(t); len(t) > 0 {
+			names = append(names, t)
+		}
+	}
+	if len(names) > 0 {
+		url += "/" + strings.Join(names, "&")
+	}
+	body, resp, err := this.send("Neo4j.GetDegree", "GET", url, "")
+	if err != nil {
+		return 0, err
+	}
+	errorList := map[int]error{
+		404: errors.New("Node not found."),
+	}
+	err = this.statusError("Neo4j.GetDegree", errorList, resp, body)
+	if err != nil || this.ignored(resp.StatusCode) {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(body))
+}
+/*
+GetRelationship(relationship id uint) returns a Relationship struct and any errors raised as error
+*/
+func (this *Neo4j) GetRelationship(id RelID) (rel *Relationship, err error) {
+	url := this.endpoint("relationship", "/relationship") + "/"
+	errorList := map[int]error{
+		404: errors.New("Relationship not found."),
+	}
+	template, err := this.receiveTemplate("Neo4j.GetRelationship", "GET", url+id.String(), "", errorList)
+	if err != nil || template == nil {
+		return rel, err
+	}
+	return template.Relationship()
+}
+/*
+GetRelationshipProperty(relationship id uint, name string) returns string of property value and any error raised as error
+*/
+func (this *Neo4j) GetRelationshipProperty(id RelID, name string) (string, error) {
+	if len(name) < 1 {
+		return "", errors.New("Property name must be at least 1 character.")
+	}
+	url := this.endpoint("relationship", "/relationship") + "/"
+	body, resp, err := this.send("Neo4j.GetRelationshipProperty", "GET", url+id.String()+"/properties/"+name, "")
+	if err != nil {
+		return "", err
+	}
+	errorList := map[int]error{
+		404: errors.New("Relationship or Property not found."),
+		204: errors.New("No properties found."),
+	}
+	return body, this.statusError("Neo4j.GetRelationshipProperty", errorList, resp, body)
+}
+/*
+GetRelationshipProperties(relationship id uint) returns a NeoTemplate struct and any errors raised as error
+*/
+func (this *Neo4j) GetRelationshipProperties(id RelID) (tmp *NeoTemplate, err error) {
+	url := this.endpoint("relationship", "/relationship") + "/"
+	body, resp, err := this.send("Neo4j.GetRelationshipProperties", "GET", url+id.String()+"/properties", "")
+	if err != nil {
+		return tmp, err
+	}
+	errorList := map[int]error{
+		404: errors.New("Relationship not found."),
+		204: errors.New("No properties found."),
+	}
+	err = this.statusError("Neo4j.GetRelationshipProperties", errorList, resp, body)
+	if err != nil || this.ignored(resp.StatusCode) {
+		return tmp, err
+	}
+	// pack json string into variable "data" so the json unmarshaler knows where to put it on struct type NeoTemplate
+	jsonData, err := this.pack("data", body)
+	if err != nil {
+		return tmp, err
+	}
+	template, err := this.unmarshal(string(jsonData))
+	if err != nil {
+		return tmp, err
+	}
+	return template[0], nil
+}
+/*
+SetRelationship(relationship id uint, data map[string]string) returns any errors raised as error
+id is the relationship id
+*/
+func (this *Neo4j) SetRelationship(id RelID, data map[string]string) error {
+	return this.SetRelationshipTyped(id, typedProperties(data))
+}
+/*
+SetRelationshipTyped(relationship id uint, data map[string]interface{}) returns any errors raised as error
+same as SetRelationship but values keep their json type
+*/
+func (this *Neo4j) SetRelationshipTyped(id RelID, data map[string]interface{}) error {
+	data, err := this.encodeProperties(data)
+	if err != nil {
+		return err
+	}
+	url := this.endpoint("relationship", "/relationship") + "/"
+	s, err := json.Marshal(data)
+	if err != nil {
+		return errors.New("Unable to Marshal Json data")
+	}
+	body, resp, err := this.send("Neo4j.SetRelationshipTyped", "PUT", url+strconv.FormatUint(uint64(id), 10)+"/properties", string(s))
+	if err != nil {
+		return err
+	}
+	errorList := map[int]error{
+		404: errors.New("Relationship not found."),
+		400: errors.New("Invalid data sent."),
+	}
+	return this.statusError("Neo4j.SetRelationshipTyped", errorList, resp, body)
+}
+/*
+DelRelationshipProperty(relationship id uint, s string) returns any errors raised as error
+pass in the id of the relationship and string as the name/key of the property to delete
+*/
+func (this *Neo4j) DelRelationshipProperty(id RelID, s string) error {
+	if len(s) < 1 {
+		return errors.New("Property name must be at least 1 character.")
+	}
+	url := this.endpoint("relationship", "/relationship") + "/"
+	body, resp, err := this.send("Neo4j.DelRelationshipProperty", "DELETE", url+id.String()+"/properties/"+s, "")
+	if err != nil {
+		return err
+	}
+	errorList := map[int]error{
+		404: errors.New("Relationship or Property not found."),
+	}
+	return this.statusError("Neo4j.DelRelationshipProperty", errorList, resp, body)
+}
+/*
+DelRelationship(relationship id uint) returns any errors raised as error
+you can pass in more than 1 id
+*/
+func (this *Neo4j) DelRelationship(id ...RelID) error {
+	url := this.endpoint("relationship", "/relationship") + "/"
+	errorList := map[int]error{
+		404: errors.New("Relationship not found."),
+	}
+	for _, i := range id {
+		// delete each relationship for every id passed in
+		body, resp, err := this.send("Neo4j.DelRelationship", "DELETE", url+strconv.FormatUint(uint64(i), 10), "")
+		if err != nil {
+			return err
+		}
+		err = this.statusError("Neo4j.DelRelationship", errorList, resp, body)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+/*
+ListRelationshipTypes() returns every relationship type in the database and any errors raised as error
+*/
+func (this *Neo4j) ListRelationshipTypes() ([]string, error) {
+	return this.getStrings("Neo4j.ListRelationshipTypes", this.endpoint("relationship_types", "/relationship/types"), map[int]error{})
+}
+/*
+CreateRelationship(src node id uint, dst node id uint, data map[string]string, relationship type string) returns a Relationship struct of the new relationship and any errors raised as error
+*/
+func (this *Neo4j) CreateRelationship(src NodeID, dst NodeID, data map[string]string, rType string) (tmp *Relationship, err error) {
+	return this.CreateRelationshipTyped(src, dst, typedProperties(data), rType)
+}
+/*
+CreateRelationshipTyped(src node id uint, dst node id uint, data map[string]interface{}, relationship type string) returns a Relationship struct of the new relationship and any errors raised as error
+same as CreateRelationship but values keep their json type
+*/
+func (this *Neo4j) CreateRelationshipTyped(src NodeID, dst NodeID, data map[string]interface{}, rType string) (tmp *Relationship, err error) {
+	data, err = this.encodeProperties(data)
+	if err != nil {
+		return tmp, err
+	}
+	dstNode, err := this.nodeURLs(dst)
+	if err != nil {
+		return tmp, err
+	}
+	srcNode, err := this.nodeURLs(src)
+	if err != nil {
+		return tmp, err
+	}
+	j := map[string]interface{}{} // empty map: keys are always strings in json, values vary
+	j["to"] = dstNode.Self
+	j["type"] = rType               // type of relationship
+	j["data"] = map[string]interface{}{} // empty array
+	j["data"] = data                     // add data to relationship
+	s, err := marshal(j)
+	if err != nil {
+		return tmp, errors.New("Unable to Marshal Json data")
+	}
+	errorList := map[int]error{
+		404: errors.New("Node or 'to' node not found."),
+		400: errors.New("Invalid data sent."),
+	}
+	template, err := this.receiveTemplate("Neo4j.CreateRelationshipTyped", "POST", srcNode.RelationshipsCreate, s, errorList) // srcNode.RelationshipsCreate actually contains the full URL
+	if err != nil || template == nil {
+		return tmp, err
+	}
+	return template.Relationship()
+}
+/* 
+SearchIdx(key string, value string, query string, category string, index type string) returns array of NeoTemplate structs and any errors raised as error
+Lucene query lang: http://lucene.apache.org/java/3_1_0/queryparsersyntax.html
+example query: the_key:the_* AND the_other_key:[1 TO 100]
+if you specifiy a query, it will not search by key/value and vice versa
+*/
+func (this *Neo4j) SearchIdx(key string, value string, query string, cat string, idxType string) ([]*NeoTemplate, error) {
+	return this.SearchIdxOrdered(key, value, query, cat, idxType, "")
+}
+
+/* 
+SearchIdxOrdered(key string, value string, query string, category string, index type string, order string) returns array of NeoTemplate structs and any errors raised as error
+same as SearchIdx but hits come back sorted by order: "index", "relevance" or "score". blank leaves the order up to neo4j
+*/
+func (this *Neo4j) SearchIdxOrdered(key string, value string, query string, cat string, idxType string, order string) ([]*NeoTemplate, error) {
+	order = strings.ToLower(strings.TrimSpace(order))
+	switch order {
+	case "", "index", "relevance", "score":
+	default:
+		return nil, errors.New("Invalid order, use index, relevance or score.")
+	}
+	url := this.indexURL(idxType)
+	url += "/" + pathEscape(cat)
+	if len(query) > 0 { // query set, ignore key/value pair
+		url += "?query=" + queryEscape(query) // url encoding, EscapeString would html escape & and quotes inside the query
+	} else { // search key, val
+		url += "/" + pathEscape(strings.TrimSpace(key)) + "/" + pathEscape(value)
+	}
+	if len(order) > 0 {
+		if strings.Contains(url, "?") {
+			url += "&order=" + order
+		} else {
+			url += "?order=" + order
+		}
+	}
+	errorList := map[int]error{
+		400: errors.New("Invalid data sent."),
+	}
+	template, err := this.receiveTemplates("Neo4j.SearchIdxOrdered", "GET", url, "", errorList)
+	if err != nil {
+		return nil, err
+	}
+	return template, nil
 }
-/*
-CreateNode(data map[string]string) returns a NeoTemplate struct and any errors raised as error
+
+/* 
+CreateIdx(node or relationship id uint, key string, value string, category string, index type string) returns any errors raised as error
+index type "relationship" indexes the relationship with that id, anything else indexes the node
+Deprecated: the id is easily taken from the wrong kind of entity, use CreateNodeIdx or CreateRelationshipIdx
 */
-func (this *Neo4j) CreateNode(data map[string]string) (tmp *NeoTemplate, err error) {
-	s, err := json.Marshal(data)
-	if err != nil {
-		return tmp, errors.New("Unable to Marshal Json data")
-	}
-	this.Method = "post"
-	url := this.URL + "/node"
-	body, err := this.send(url, string(s))
-	if err != nil {
-		return tmp, err
+func (this *Neo4j) CreateIdx(id uint64, key string, value string, cat string, idxType string) error {
+	if strings.ToLower(idxType) == "relationship" {
+		return this.CreateRelationshipIdx(RelID(id), key, value, cat)
 	}
-	template, err := this.unmarshal(body) // json.Unmarshal wrapper with some type assertions etc
-	if err != nil {
-		return tmp, err
+	return this.CreateNodeIdx(NodeID(id), key, value, cat)
+}
+/*
+CreateNodeIdx(node id uint, key string, value string, category string) returns any errors raised as error
+adds the node to the node index category under key/value, a blank category means "idx_nodes"
+*/
+func (this *Neo4j) CreateNodeIdx(id NodeID, key string, value string, cat string) error {
+	if id < 1 {
+		return errors.New("Invalid node id specified.")
 	}
-	errorList := map[int]error{
-		400: errors.New("Invalid data sent."),
+	if len(cat) < 1 { // default, generic, index category
+		cat = "idx_nodes"
 	}
-	return template[0], this.NewError(errorList)
+	self := this.endpoint("node", "/node") + "/" + id.String() // urls are derived from the id, nothing is fetched first
+	return this.addToIdx("Neo4j.CreateNodeIdx", this.indexURL("node"), self, key, value, cat)
 }
 /*
-GetNode(id uint) returns a NeoTemplate struct and any errors raised as error
+CreateRelationshipIdx(relationship id uint, key string, value string, category string) returns any errors raised as error
+adds the relationship to the relationship index category under key/value, a blank category means "idx_relationships"
 */
-func (this *Neo4j) GetNode(id uint64) (tmp *NeoTemplate, err error) {
+func (this *Neo4j) CreateRelationshipIdx(id RelID, key string, value string, cat string) error {
 	if id < 1 {
-		return tmp, errors.New("Invalid node id specified.")
+		return errors.New("Invalid relationship id specified.")
 	}
-	this.Method = "get"
-	url := this.URL + "/node/"
-	body, err := this.send(url+strconv.FormatUint(uint64(id), 10), "") // convert uint -> string and send http request
-	if err != nil {
-		return tmp, err
+	if len(cat) < 1 {
+		cat = "idx_relationships"
 	}
-	template, err := this.unmarshal(body) // json.Unmarshal wrapper with some type assertions etc
+	self := this.endpoint("relationship", "/relationship") + "/" + id.String()
+	return this.addToIdx("Neo4j.CreateRelationshipIdx", this.indexURL("relationship"), self, key, value, cat)
+}
+// adds the node or relationship at url self to the index at url
+func (this *Neo4j) addToIdx(op string, url string, self string, key string, value string, cat string) error {
+	url += "/" + pathEscape(cat) + "/" + pathEscape(key) + "/" + pathEscape(value) + "/"
+	body, resp, err := this.send(op, "POST", url, strconv.Quote(self)) // add double quotes around the node url as neo4j expects
 	if err != nil {
-		return tmp, err
+		return err
 	}
 	errorList := map[int]error{
-		404: errors.New("Node not found."),
+		400: errors.New("Invalid data sent."),
+		404: errors.New("Node or relationship not found."),
 	}
-	return template[0], this.NewError(errorList)
+	return this.statusError(op, errorList, resp, body)
 }
 /*
-GetRelationshipsOnNode(node id uint, name string, direction string) returns an array of NeoTemplate structs containing relationship data and any errors raised as error
+CreateUniqueNode(key string, value string, data map[string]string, category string, uniqueness string) returns a NeoTemplate struct, whether the node already existed and any errors raised as error
+uniqueness is "get_or_create" (default) which hands back the indexed node if there is one, or "create_or_fail" which raises an error instead
+the node is created with data and indexed under key/value in one atomic operation
 */
-func (this *Neo4j) GetRelationshipsOnNode(id uint64, name string, direction string) (map[int]*NeoTemplate, error) {
-	node, err := this.GetNode(id) // find properties for node
-	if err != nil {
-		return nil, err
+func (this *Neo4j) CreateUniqueNode(key string, value string, data map[string]string, cat string, uniqueness string) (tmp *NeoTemplate, existed bool, err error) {
+	if len(cat) < 1 || len(strings.TrimSpace(key)) < 1 {
+		return tmp, false, errors.New("Index category and key must be at least 1 character.")
 	}
-	this.Method = "get"
-	direction = strings.ToLower(direction)
-	url := ""
-	switch direction {
-	case "in":
-		url = node.RelationshipsIn
-	case "out":
-		url = node.RelationshipsOut
-	case "all":
-		fallthrough
-	default:
-		url = node.RelationshipsAll
+	uniqueness = strings.ToLower(uniqueness)
+	if uniqueness != "create_or_fail" {
+		uniqueness = "get_or_create"
 	}
-	body, err := this.send(url+"/"+name, "")
+	err = this.validate(typedProperties(data))
 	if err != nil {
-		return nil, err
+		return tmp, false, err
 	}
-	template, err := this.unmarshal(body)
+	props, err := this.newNodeProperties(typedProperties(data))
 	if err != nil {
-		return nil, err
-	}
-	errorList := map[int]error{
-		404: errors.New("Node not found."),
+		return tmp, false, err
 	}
-	return template, this.NewError(errorList)
-}
-/*
-SetRelationship(relationship id uint, data map[string]string) returns any errors raised as error
-id is the relationship id
-*/
-func (this *Neo4j) SetRelationship(id uint64, data map[string]string) error {
-	this.Method = "put"
-	url := this.URL + "/relationship/"
-	s, err := json.Marshal(data)
+	j := map[string]interface{}{} // empty map: keys are always strings in json, values vary
+	j["key"] = strings.TrimSpace(key)
+	j["value"] = value
+	j["properties"] = props
+	s, err := json.Marshal(j)
 	if err != nil {
-		return errors.New("Unable to Marshal Json data")
+		return tmp, false, errors.New("Unable to Marshal Json data")
 	}
-	_, err = this.send(url+strconv.FormatUint(uint64(id), 10)+"/properties", string(s))
+	body, resp, err := this.send("Neo4j.CreateUniqueNode", "POST", this.indexURL("node")+"/"+pathEscape(cat)+"?uniqueness="+uniqueness, string(s))
 	if err != nil {
-		return err
+		return tmp, false, err
 	}
+	existed = resp.StatusCode == 200 || resp.StatusCode == 409 // 201 means it was created
 	errorList := map[int]error{
-		404: errors.New("Relationship not found."),
 		400: errors.New("Invalid data sent."),
+		409: errors.New("Node already exists in index."),
 	}
-	return this.NewError(errorList)
-}
-/*
-DelRelationship(relationship id uint) returns any errors raised as error
-you can pass in more than 1 id
-*/
-func (this *Neo4j) DelRelationship(id ...uint64) error {
-	this.Method = "delete"
-	url := this.URL + "/relationship/"
-	for _, i := range id {
-		// delete each relationship for every id passed in
-		_, err := this.send(url+strconv.FormatUint(uint64(i), 10), "")
-		if err != nil {
-			return err
-		}
+	err = this.statusError("Neo4j.CreateUniqueNode", errorList, resp, body)
+	if err != nil || this.ignored(resp.StatusCode) { // the body is the error or nothing, not the node
+		return nil, existed, err
 	}
-	errorList := map[int]error{
-		404: errors.New("Relationship not found."),
+	template, err := this.unmarshal(body)
+	if err != nil || len(template) < 1 {
+		return nil, existed, err
 	}
-	return this.NewError(errorList)
+	return template[0], existed, nil
 }
 /*
-CreateRelationship(src node id uint, dst node id uint, data map[string]string, relationship type string) returns any errors raised as error
+CreateIdxWithConfig(category string, index type string, config map[string]string) returns any errors raised as error
+creates the index itself up front, ie: config {"type": "fulltext", "provider": "lucene"} for a fulltext index
+indexes created implicitly by CreateIdx always get the default (exact) configuration
 */
-func (this *Neo4j) CreateRelationship(src uint64, dst uint64, data map[string]string, rType string) error {
-	dstNode, err := this.GetNode(dst) // find properties for destination node so we can tie it into the relationship
-	if err != nil {
-		return err
-	}
-	srcNode, err := this.GetNode(src) // find properties for src node..
-	if err != nil {
-		return err
+func (this *Neo4j) CreateIdxWithConfig(cat string, idxType string, config map[string]string) error {
+	if len(cat) < 1 {
+		return errors.New("Index category must be at least 1 character.")
 	}
+	url := this.indexURL(idxType)
 	j := map[string]interface{}{} // empty map: keys are always strings in json, values vary
-	j["to"] = dstNode.Self
-	j["type"] = rType               // type of relationship
-	j["data"] = map[string]string{} // empty array
-	j["data"] = data                // add data to relationship
+	j["name"] = cat
+	if config != nil {
+		j["config"] = config
+	}
 	s, err := json.Marshal(j)
 	if err != nil {
 		return errors.New("Unable to Marshal Json data")
 	}
-	this.Method = "post"
-	_, err = this.send(srcNode.RelationshipsCreate, string(s)) // srcNode.RelationshipsCreate actually contains the full URL
+	body, resp, err := this.send("Neo4j.CreateIdxWithConfig", "POST", url, string(s))
 	if err != nil {
 		return err
 	}
 	errorList := map[int]error{
-		404: errors.New("Node or 'to' node not found."),
 		400: errors.New("Invalid data sent."),
 	}
-	return this.NewError(errorList)
+	return this.statusError("Neo4j.CreateIdxWithConfig", errorList, resp, body)
 }
-/* 
-SearchIdx(key string, value string, query string, category string, index type string) returns array of NeoTemplate structs and any errors raised as error
-Lucene query lang: http://lucene.apache.org/java/3_1_0/queryparsersyntax.html
-example query: the_key:the_* AND the_other_key:[1 TO 100]
-if you specifiy a query, it will not search by key/value and vice versa
+/*
+RemoveFromIdx(node or relationship id uint, key string, value string, category string, index type string) returns any errors raised as error
+leave value blank to remove every entry for the key, leave key and value blank to remove the entity from the index altogether
+Deprecated: the id is easily taken from the wrong kind of entity, use RemoveNodeFromIdx or RemoveRelationshipFromIdx
 */
-func (this *Neo4j) SearchIdx(key string, value string, query string, cat string, idxType string) (map[int]*NeoTemplate, error) {
-	url := this.URL + "/index/"
+func (this *Neo4j) RemoveFromIdx(id uint64, key string, value string, cat string, idxType string) error {
 	if strings.ToLower(idxType) == "relationship" {
-		url += "relationship"
-	} else {
-		url += "node"
-	}
-	url += "/" + cat
-	if len(query) > 0 { // query set, ignore key/value pair
-		url += "?query=" + this.EscapeString(query)
-	} else { // search key, val
-		url += "/" + strings.TrimSpace(key) + "/" + this.EscapeString(value)
+		return this.RemoveRelationshipFromIdx(RelID(id), key, value, cat)
 	}
-	this.Method = "get"
-	body, err := this.send(url, "")
-	if err != nil {
-		return nil, err
+	return this.RemoveNodeFromIdx(NodeID(id), key, value, cat)
+}
+/*
+RemoveNodeFromIdx(node id uint, key string, value string, category string) returns any errors raised as error
+leave value blank to remove every entry for the key, leave key and value blank to remove the node from the index altogether
+*/
+func (this *Neo4j) RemoveNodeFromIdx(id NodeID, key string, value string, cat string) error {
+	return this.removeFromIdx("Neo4j.RemoveNodeFromIdx", this.indexURL("node"), uint64(id), key, value, cat)
+}
+/*
+RemoveRelationshipFromIdx(relationship id uint, key string, value string, category string) returns any errors raised as error
+see RemoveNodeFromIdx
+*/
+func (this *Neo4j) RemoveRelationshipFromIdx(id RelID, key string, value string, cat string) error {
+	return this.removeFromIdx("Neo4j.RemoveRelationshipFromIdx", this.indexURL("relationship"), uint64(id), key, value, cat)
+}
+// removes the entries of the node or relationship id from the index at url
+func (this *Neo4j) removeFromIdx(op string, url string, id uint64, key string, value string, cat string) error {
+	if len(cat) < 1 {
+		return errors.New("Index category must be at least 1 character.")
+	}
+	url += "/" + pathEscape(cat)
+	key = strings.TrimSpace(key)
+	if len(key) > 0 {
+		url += "/" + pathEscape(key)
+		if len(value) > 0 {
+			url += "/" + pathEscape(value) // EscapeString would html escape & and quotes
+		}
+	} else if len(value) > 0 {
+		return errors.New("Index key is required when removing by value.")
 	}
-	template, err := this.unmarshal(body)
+	body, resp, err := this.send(op, "DELETE", url+"/"+strconv.FormatUint(id, 10), "")
 	if err != nil {
-		return nil, err
+		return err
 	}
 	errorList := map[int]error{
-		400: errors.New("Invalid data sent."),
+		404: errors.New("Index or entry not found."),
 	}
-	return template, this.NewError(errorList)
+	return this.statusError(op, errorList, resp, body)
 }
-
-/* 
-CreateIdx(node id uint, key string, value string, category string, index type string) returns any errors raised as error
+/*
+ListIdx(index type string) returns the configuration of every index keyed by index name and any errors raised as error
+index type is either "node" or "relationship", config holds keys like "provider", "type" and "template"
 */
-func (this *Neo4j) CreateIdx(id uint64, key string, value string, cat string, idxType string) error {
-	template, err := this.GetNode(id)
+func (this *Neo4j) ListIdx(idxType string) (map[string]map[string]string, error) {
+	url := this.indexURL(idxType)
+	body, resp, err := this.send("Neo4j.ListIdx", "GET", url, "")
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if len(cat) < 1 {
-		idxType = "idx_nodes" // default, generic, index type
+	err = this.statusError("Neo4j.ListIdx", map[int]error{}, resp, body)
+	if err != nil {
+		return nil, err
 	}
-	self := template.Self
-	url := this.URL + "/index/"
-	if strings.ToLower(idxType) == "relationship" {
-		url += "relationship"
-	} else {
-		url += "node"
+	idx := map[string]map[string]string{}
+	if resp.StatusCode == 204 || len(strings.TrimSpace(body)) < 1 { // no indexes at all
+		return idx, nil
 	}
-	url += "/" + cat + "/" + key + "/" + value + "/"
-	this.Method = "post"
-	_, err = this.send(url, strconv.Quote(self)) // add double quotes around the node url as neo4j expects
-	errorList := map[int]error{
-		400: errors.New("Invalid data sent."),
+	err = json.Unmarshal([]byte(body), &idx)
+	if err != nil {
+		return nil, err
 	}
-	return this.NewError(errorList)
+	return idx, nil
 }
 /*
 Traverse(node id uint, return type string, order string, uniqueness string, relationships map[string]string, depth int, prune map[string]string, filter map[string]string) returns array of NeoTemplate structs and any errors raised as error
+return type, order and uniqueness take the values of the ReturnType, TraversalOrder and Uniqueness constants, blank uses the server default
+TraversalFrom builds the same request without the long list of positional arguments
 */
-func (this *Neo4j) Traverse(id uint64, returnType string, order string, uniqueness string, relationships map[string]string, depth int, prune map[string]string, filter map[string]string) (map[int]*NeoTemplate, error) {
-	node, err := this.GetNode(id) // find properties for destination node
+func (this *Neo4j) Traverse(id NodeID, returnType string, order string, uniqueness string, relationships map[string]string, depth int, prune map[string]string, filter map[string]string) ([]*NeoTemplate, error) {
+	rt, err := ReturnType(returnType).check()
+	if err != nil {
+		return nil, err
+	}
+	o, err := TraversalOrder(order).check()
+	if err != nil {
+		return nil, err
+	}
+	u, err := Uniqueness(uniqueness).check()
 	if err != nil {
 		return nil, err
 	}
 	j := map[string]interface{}{} // empty map: keys are always strings in json, values vary
-	j["order"] = order
+	if len(o) > 0 {
+		j["order"] = o
+	}
 	j["max depth"] = depth
-	j["uniqueness"] = uniqueness
+	if len(u) > 0 {
+		j["uniqueness"] = u
+	}
 	if relationships != nil {
+		if _, err := Direction(relationships["direction"]).check(); err != nil {
+			return nil, err
+		}
 		j["relationships"] = map[string]string{} // empty array
 		j["relationships"] = relationships       // like: { "type": "KNOWS", "direction": "all" }
 	}
 	if prune != nil {
+		if err := checkEvaluator("prune evaluator", prune["language"], prune["name"], prune["body"], "none"); err != nil {
+			return nil, err
+		}
 		j["prune evaluator"] = map[string]string{} // empty array
 		j["prune evaluator"] = prune               // like: {  "language": "javascript", "body": "position.endNode().getProperty('date')>1234567;" }
 	}
 	if filter != nil {
+		if err := checkEvaluator("return filter", filter["language"], filter["name"], filter["body"], returnFilterBuiltins...); err != nil {
+			return nil, err
+		}
 		j["return filter"] = map[string]string{} // empty array
 		j["return filter"] = filter              // like: { "language": "builtin","name": "all" }
 	}
-	s, err := json.Marshal(j)
-	if err != nil {
-		return nil, errors.New("Unable to Marshal Json data")
-	}
-	this.Method = "post"
-	returnType = strings.ToLower(returnType)
-	switch returnType { // really just a list of allowed values and anything else is replaced with "node"
-	case "relationship":
-	case "path":
-	case "fullpath":
-	case "node":
-	default:
-		returnType = "node"
-	}
-	url := strings.Replace(node.Traverse, "{returnType}", returnType, 1) // neo4j returns the traverse URL with the literal "{returnType}" at the end
-	body, err := this.send(url, string(s))
+	return this.traverse("Neo4j.Traverse", id, rt, j)
+}
+
+// sends the traversal description j starting at node id
+func (this *Neo4j) traverse(op string, id NodeID, returnType ReturnType, j map[string]interface{}) ([]*NeoTemplate, error) {
+	node, err := this.nodeURLs(id)
 	if err != nil {
 		return nil, err
 	}
-	template, err := this.unmarshal(body)
+	s, err := marshal(j)
 	if err != nil {
-		return nil, err
+		return nil, errors.New("Unable to Marshal Json data")
 	}
+	url := strings.Replace(node.Traverse, "{returnType}", string(returnType), 1) // neo4j returns the traverse URL with the literal "{returnType}" at the end
 	errorList := map[int]error{
 		404: errors.New("Node not found."),
 	}
-	return template, this.NewError(errorList)
+	template, err := this.receiveTemplates(op, "POST", url, s, errorList)
+	if err != nil {
+		return nil, err
+	}
+	return template, nil
 }
 
 /* 
 TraversePath(src node id uint, dst node id uint, relationships map[string]string, depth uint, algorithm string, paths bool) returns array of NeoTemplate structs and any errors raised as error
+see Paths to convert the result into Path structs
+*/
+func (this *Neo4j) TraversePath(src NodeID, dst NodeID, relationships map[string]string, depth uint, algo string, paths bool) ([]*NeoTemplate, error) {
+	j := map[string]interface{}{} // empty map: keys are always strings in json, values vary
+	j["max depth"] = depth
+	j["algorithm"] = algo
+	return this.traversePath("Neo4j.TraversePath", src, dst, relationships, j, paths)
+}
+/*
+TraversePathDijkstra(src node id uint, dst node id uint, relationships map[string]string, cost property string, default cost float64, paths bool) returns array of NeoTemplate structs and any errors raised as error
+finds the cheapest path(s) with the dijkstra algorithm, the cost of a relationship is read from its cost property
+relationships without the property cost default cost
 */
-func (this *Neo4j) TraversePath(src uint64, dst uint64, relationships map[string]string, depth uint, algo string, paths bool) (map[int]*NeoTemplate, error) {
-	dstNode, err := this.GetNode(dst) // find properties for destination node
+func (this *Neo4j) TraversePathDijkstra(src NodeID, dst NodeID, relationships map[string]string, costProperty string, defaultCost float64, paths bool) ([]*NeoTemplate, error) {
+	if len(strings.TrimSpace(costProperty)) < 1 {
+		return nil, errors.New("Cost property must be at least 1 character.")
+	}
+	j := map[string]interface{}{} // empty map: keys are always strings in json, values vary
+	j["algorithm"] = "dijkstra"
+	j["cost_property"] = strings.TrimSpace(costProperty)
+	j["default_cost"] = defaultCost
+	return this.traversePath("Neo4j.TraversePathDijkstra", src, dst, relationships, j, paths)
+}
+// sends the path search j from src to dst
+func (this *Neo4j) traversePath(op string, src NodeID, dst NodeID, relationships map[string]string, j map[string]interface{}, paths bool) ([]*NeoTemplate, error) {
+	dstNode, err := this.nodeURLs(dst)
 	if err != nil {
 		return nil, err
 	}
-	srcNode, err := this.GetNode(src) // find properties for src node..
+	srcNode, err := this.nodeURLs(src)
 	if err != nil {
 		return nil, err
 	}
-	j := map[string]interface{}{} // empty map: keys are always strings in json, values vary
+	if _, err := Direction(relationships["direction"]).check(); err != nil {
+		return nil, err
+	}
 	j["to"] = dstNode.Self
-	j["max depth"] = depth
-	j["algorithm"] = algo
 	j["relationships"] = map[string]string{} // empty array
 	j["relationships"] = relationships       // specify relationships like type: "KNOWS" direction: "all"
 	s, err := json.Marshal(j)
 	if err != nil {
 		return nil, errors.New("Unable to Marshal Json data")
 	}
-	this.Method = "post"
 	url := srcNode.Self
 	if paths {
 		url += "/paths"
 	} else {
 		url += "/path"
 	}
-	body, err := this.send(url, string(s))
-	if err != nil {
-		return nil, err
+	errorList := map[int]error{
+		404: errors.New("No path found using current algorithm and parameters"),
 	}
-	template, err := this.unmarshal(body)
+	template, err := this.receiveTemplates(op, "POST", url, string(s), errorList)
 	if err != nil {
 		return nil, err
 	}
-	errorList := map[int]error{
-		404: errors.New("No path found using current algorithm and parameters"),
-	}
-	return template, this.NewError(errorList)
+	return template, nil
+}
+// lower cases the direction and makes sure it is one neo4j knows. blank means DirAll
+func (this Direction) check() (Direction, error) {
+	d := Direction(strings.ToLower(strings.TrimSpace(string(this))))
+	switch d {
+	case "":
+		return DirAll, nil
+	case DirIn, DirOut, DirAll:
+		return d, nil
+	}
+	return d, errors.New("Invalid direction " + string(this) + ", use in, out or all.")
 }
 /* shamelessly taken from golang html pkg */
 func (this *Neo4j) EscapeString(s string) string {
 	if strings.IndexAny(s, escapedChars) == -1 {
 		return s
 	}
-	buf := bytes.NewBuffer(nil)
+	buf := getBuffer()
+	defer putBuffer(buf)
 	this.escape(buf, s)
 	return buf.String()
 }
@@ -590,8 +1303,8 @@ func (this *Neo4j) escape(buf *bytes.Buffer, s string) {
 			esc = "%5B"
 		case ']':
 			esc = "%5D"
-		default:
-			panic("unrecognized escape character")
+		default: // escapedChars and the cases above got out of step, percent-encode rather than bring the program down
+			esc = "%" + string(upperHex[s[i]>>4]) + string(upperHex[s[i]&0xF])
 		}
 		s = s[i+1:]
 		buf.WriteString(esc)
@@ -602,84 +1315,228 @@ func (this *Neo4j) escape(buf *bytes.Buffer, s string) {
 // packs string literal into json object structure around variable "varName"
 // data string should already be in json format
 func (this *Neo4j) pack(name string, data string) ([]byte, error) {
-	buf := new(bytes.Buffer)
+	buf := getBuffer()
+	defer putBuffer(buf)
 	err := json.Compact(buf, []byte("{ \""+name+"\": "+data+" } ")) // pkg data into new json string then compact() it onto our empty buffer
 	if err != nil {
 		return nil, err
 	}
-	return buf.Bytes(), err
+	return append([]byte(nil), buf.Bytes()...), nil // copy, buf goes back to the pool
 }
-func (this *Neo4j) send(url string, data string) (string, error) {
-	var (
-		resp *http.Response // http response
-		buf  bytes.Buffer   // contains http response body
-		err  error
-	)
+// sends the request and returns the response body along with the response, whose body is read and closed already
+// every call has its own so the client can be shared between goroutines. op is the client method sending it, ie: Neo4j.GetNode
+func (this *Neo4j) send(op string, method string, url string, data string) (string, *http.Response, error) {
+	buf := getBuffer() // contains http response body
+	defer putBuffer(buf)
+	resp, err := this.do(op, method, url, data)
+	if err != nil {
+		return "", nil, err
+	}
+	defer resp.Body.Close()
+	if this.ignored(resp.StatusCode) { // see MapStatus
+		return "null", resp, nil
+	}
+	_, err = buf.ReadFrom(resp.Body)
+	if err != nil {
+		return "", nil, err
+	}
+	return buf.String(), resp, nil // the calling method should check the status code with statusError() and determine if the operation was successful or not.
+}
+// sends the request and decodes the json response body straight into v, without buffering it first
+// the status code is checked against errorList before anything is decoded
+func (this *Neo4j) receive(op string, method string, url string, data string, errorList map[int]error, v interface{}) error {
+	resp, err := this.do(op, method, url, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	err = this.responseError(op, errorList, resp)
+	if err != nil || this.ignored(resp.StatusCode) { // see MapStatus, v is left as it is
+		return err
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+// same as receive but hands back the response as NeoTemplates, see unmarshal
+func (this *Neo4j) receiveTemplates(op string, method string, url string, data string, errorList map[int]error) ([]*NeoTemplate, error) {
+	var raw interface{}
+	err := this.receive(op, method, url, data, errorList, &raw)
+	if err != nil {
+		return nil, err
+	}
+	return this.templates(raw)
+}
+// same as receiveTemplates for a response holding a single node or relationship, nil when there is none
+func (this *Neo4j) receiveTemplate(op string, method string, url string, data string, errorList map[int]error) (*NeoTemplate, error) {
+	template, err := this.receiveTemplates(op, method, url, data, errorList)
+	if err != nil || len(template) < 1 {
+		return nil, err
+	}
+	return template[0], nil
+}
+// sends the request and hands back the response with the body still unread, the caller has to close it
+// idempotent requests are sent again as long as the Retry option allows
+func (this *Neo4j) do(op string, method string, url string, data string) (*http.Response, error) {
 	if len(url) < 1 {
-		url = this.URL + "node" // default path
-	}
-	client := new(http.Client)
-	switch strings.ToLower(this.Method) { // which http method
-	case "delete":
-		req, e := http.NewRequest("DELETE", url, nil)
-		if e != nil {
-			err = e
-			break
+		url = this.endpoint("node", "/node") // default path
+	}
+	method = strings.ToUpper(method) // which http method
+	switch method {
+	case "POST", "PUT", "DELETE":
+	default:
+		method = "GET"
+	}
+	for n := 1; ; n++ {
+		resp, err := this.attempt(op, method, url, data)
+		if !this.retry.retryable(method, n, resp, err) {
+			if err != nil {
+				return nil, operationError(op, err)
+			}
+			return resp, nil
 		}
-		this.setAuth(*req)
-		resp, err = client.Do(req)
-	case "post":
-		body := strings.NewReader(data)
-		req, e := http.NewRequest("POST", url, body)
-		if e != nil {
-			err = e
-			break
+		if resp != nil {
+			resp.Body.Close()
+			this.log().Info("neo4j: retrying request", "method", method, "url", redact(url), "attempt", n+1, "status", resp.StatusCode)
+		} else {
+			this.log().Info("neo4j: retrying request", "method", method, "url", redact(url), "attempt", n+1, "error", err)
 		}
-		req.Header.Set("Content-Type", "application/json")
-		this.setAuth(*req)
-		resp, err = client.Do(req)
-	case "put":
-		body := strings.NewReader(data)
-		req, e := http.NewRequest("PUT", url, body)
-		if e != nil {
-			err = e
-			break
+		this.observeRetry(op, method)
+		err = this.retry.wait(this.Context(), n)
+		if err != nil {
+			return nil, operationError(op, err)
 		}
+	}
+}
+// sends the request once
+func (this *Neo4j) attempt(op string, method string, url string, data string) (*http.Response, error) {
+	var body io.Reader
+	if method == "POST" || method == "PUT" {
+		body = strings.NewReader(data)
+	}
+	ctx, cancel := this.requestContext()
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if body != nil {
 		req.Header.Set("Content-Type", "application/json")
-		this.setAuth(*req)
-		resp, err = client.Do(req)
-	case "get":
-		fallthrough
-	default:
-		req, e := http.NewRequest("GET", url, nil)
-                if e != nil {
-                        err = e
-                        break
-                }
-		this.setAuth(*req)
-                resp, err = client.Do(req)
-
 	}
+	if !this.noGzip {
+		req.Header.Set("Accept-Encoding", "gzip") // set by hand so it works whatever transport the http.Client has
+	}
+	if this.stream {
+		req.Header.Set("X-Stream", "true")
+	}
+	this.setAuth(req)
+	client := this.Client
+	if client == nil { // Neo4j struct not made by NewNeo4j
+		client = defaultClient
+	}
+	if this.limiter != nil {
+		err = this.limiter.Wait(ctx)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+	}
+	ticket, err := this.breaker.allow()
 	if err != nil {
-		return "", err
+		cancel()
+		return nil, err
 	}
-	defer func() {
-		if resp.Body != nil {
+	if this.debug {
+		this.dumpRequest(req, data)
+	}
+	start := time.Now()
+	resp, err := client.Do(req)
+	this.breaker.record(ticket, err)
+	if err != nil {
+		cancel()
+		this.log().Debug("neo4j: request failed", "method", method, "url", req.URL.Redacted(), "duration", time.Since(start), "error", err)
+		this.observe(op, method, 0, start)
+		return nil, err
+	}
+	this.observe(op, method, resp.StatusCode, start)
+	this.log().Debug("neo4j: request", "method", method, "url", req.URL.Redacted(), "status", resp.StatusCode, "duration", time.Since(start))
+	resp.Body = &cancelBody{resp.Body, cancel}
+	if strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") && !resp.Uncompressed {
+		err = gunzipBody(resp)
+		if err != nil {
 			resp.Body.Close()
+			return nil, err
 		}
-	}()
-	_, err = buf.ReadFrom(resp.Body)
+	}
+	if this.debug {
+		this.dumpResponse(resp) // after decompressing so the body is readable
+	}
+	return resp, nil
+}
+// sets the Authorization header when the client has credentials
+func (this *Neo4j) setAuth(req *http.Request) {
+	user, password, token := this.credentials()
+	if len(token) > 0 {
+		req.Header.Set("Authorization", tokenHeader(token))
+		return
+	}
+	if len(user) > 0 || len(password) > 0 {
+		req.SetBasicAuth(user, password)
+	}
+}
+func (this NodeID) String() string {
+	return strconv.FormatUint(uint64(this), 10)
+}
+func (this RelID) String() string {
+	return strconv.FormatUint(uint64(this), 10)
+}
+// ID of a NeoTemplate holding a node
+func (this *NeoTemplate) NodeID() NodeID {
+	return NodeID(this.ID)
+}
+// ID of a NeoTemplate holding a relationship
+func (this *NeoTemplate) RelID() RelID {
+	return RelID(this.ID)
+}
+// converts a relationship held in a NeoTemplate into a Relationship struct
+func (this *NeoTemplate) Relationship() (*Relationship, error) {
+	if len(this.Start) < 1 || len(this.End) < 1 {
+		return nil, errors.New("Template does not hold a relationship.")
+	}
+	start, err := idFromURL(this.Start)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	this.StatusCode = resp.StatusCode // the calling method should do more inspection with chkStatusCode() method and determine if the operation was successful or not.
-	return buf.String(), nil
+	end, err := idFromURL(this.End)
+	if err != nil {
+		return nil, err
+	}
+	rel := &Relationship{
+		ID:         RelID(this.ID),
+		StartID:    NodeID(start),
+		EndID:      NodeID(end),
+		Type:       this.Type,
+		Data:       this.Data,
+		Self:       this.Self,
+		Start:      this.Start,
+		End:        this.End,
+		Properties: this.Properties,
+	}
+	return rel, nil
 }
-// sets Basic HTTP Auth
-func (this *Neo4j) setAuth(req http.Request) {
-	if len(this.Username) > 0 || len(this.Password) > 0 {
-        	req.SetBasicAuth(this.Username, this.Password)
+// widens string properties for the *Typed methods
+func typedProperties(data map[string]string) map[string]interface{} {
+	if data == nil {
+		return nil
+	}
+	typed := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		typed[k] = v
 	}
+	return typed
+}
+// pulls the trailing ID off a neo4j URL like http://127.0.0.1:7474/db/data/node/123
+func idFromURL(s string) (uint64, error) {
+	slice := strings.Split(s, "/")                           // slice string on each '/' char
+	return strconv.ParseUint(slice[len(slice)-1], 10, 64) // and pull off the last part which is the ID then string -> uint
 }
 // this function unmarshals the individual node of data(or relationship etc). 
 // called internally to build the dataset of records returned from neo4j
@@ -699,9 +1556,17 @@ func (this *Neo4j) unmarshalNode(template map[string]interface{}) (*NeoTemplate,
 					node.Data = vv                                    
 				case "extensions":
 					node.Extensions = vv
+				case "metadata":
+					if labels, ok := vv["labels"].([]interface{}); ok {
+						for _, l := range labels {
+							if s, ok := l.(string); ok {
+								node.Labels = append(node.Labels, s)
+							}
+						}
+					}
 				}
 			default:
-				log.Printf("*Notice: Unknown type in JSON stream: %T from key: %v\n", vv, k)
+				this.log().Warn("neo4j: unknown type in json response", "type", fmt.Sprintf("%T", vv), "key", k)
 			}
 		} else { // to my knowledge neo4j is only going to pass strings and arrays so if map assertion failed above try an array instead
 			data, assert = v.([]interface{}) // normal array?
@@ -723,8 +1588,7 @@ func (this *Neo4j) unmarshalNode(template map[string]interface{}) (*NeoTemplate,
 					case "self":
 						node.Self, _ = data.(string) // cast it to a string with type assertion
 						// "self" provides easy access to the ID property of the node(relationship, index,etc), we'll take advantage and axe it off right now
-						selfSlice := strings.Split(string(node.Self), "/")         // slice string "Self" on each '/' char, -1 gets all instances
-						id, atouiErr := strconv.ParseUint(selfSlice[len(selfSlice)-1], 10, 0) // and pull off the last part which is the ID then string -> uint
+						id, atouiErr := idFromURL(node.Self)
 						if atouiErr != nil {
 							return nil, atouiErr
 						}
@@ -754,6 +1618,15 @@ func (this *Neo4j) unmarshalNode(template map[string]interface{}) (*NeoTemplate,
 					case "indexed": // indices use this
 						node.Indexed, _ = data.(string)
 					}
+				} else if f, ok := v.(float64); ok { // numbers
+					switch k {
+					case "score": // index search hits use this
+						node.Score = f
+					case "length": // paths
+						node.Length = strconv.Itoa(int(f))
+					case "weight": // paths found by a weighted algorithm like dijkstra
+						node.Weight = f
+					}
 				}
                 
 			}
@@ -765,39 +1638,48 @@ func (this *Neo4j) unmarshalNode(template map[string]interface{}) (*NeoTemplate,
 json.Unmarshal wrapper
 extracts json data into new interface and returns populated array of interfaces and any errors raised
 */
-func (this *Neo4j) unmarshal(s string) (dataSet map[int]*NeoTemplate, err error) {
-	var (
-		templateNode map[string]interface{}   // blank interface for json.Unmarshal; used for node lvl data
-		templateSet  []map[string]interface{} // array of blank interfaces for json.Unmarshal
-	)
-	dataSet = make(map[int]*NeoTemplate)           // make it ready for elements
-	err = json.Unmarshal([]byte(s), &templateNode) // unmarshal json data into blank interface. the json pkg will populate with the proper data types
-	if err != nil {                                // fails on multiple results
-		err = json.Unmarshal([]byte(s), &templateSet) // if unable to unmarshal into single template, try an array of templates instead. If that fails, raise an error
+func (this *Neo4j) unmarshal(s string) ([]*NeoTemplate, error) {
+	var raw interface{} // the json pkg will populate with the proper data types
+	err := json.Unmarshal([]byte(s), &raw)
+	if err != nil {
+		return nil, err
+	}
+	return this.templates(raw)
+}
+// converts a decoded json object or array of objects into NeoTemplates
+func (this *Neo4j) templates(raw interface{}) ([]*NeoTemplate, error) {
+	switch v := raw.(type) {
+	case nil: // null, see MapStatus
+		return nil, nil
+	case map[string]interface{}:
+		template, err := this.unmarshalNode(v)
 		if err != nil {
 			return nil, err
 		}
-		for _, v := range templateSet {
-			data, err := this.unmarshalNode(v) // append NeoTemplate into the data set                             
+		return []*NeoTemplate{template}, nil // just a single result
+	case []interface{}:
+		dataSet := make([]*NeoTemplate, 0, len(v))
+		for _, e := range v {
+			node, ok := e.(map[string]interface{})
+			if !ok {
+				return nil, errors.New("Unable to parse response, expected a json object.")
+			}
+			data, err := this.unmarshalNode(node)
 			if err != nil {
 				return nil, err
 			}
-			dataSet[len(dataSet)] = data // new array element containing data
-		}
-	} else {
-		template, err := this.unmarshalNode(templateNode)
-		if err != nil {
-			return nil, err
+			dataSet = append(dataSet, data) // keeps the order neo4j returned them in
 		}
-		dataSet[0] = template // just a single result
+		return dataSet, nil
 	}
-	return
+	return nil, errors.New("Unable to parse response, expected a json object or array.")
 }
-func (this *Neo4j) NewError(errorList map[int]error) error {
+// returns the error errorList holds for the status code of a response, if any
+func (this *Neo4j) NewError(errorList map[int]error, code int) error {
 	if errorList != nil {
 		errorList[500] = errors.New("Fatal Error 500.") // everything can return a 500 error
 	}
-	err := &Error{errorList, this.StatusCode}
+	err := &Error{errorList, code}
 	return err.check()
 }
 // checks the status code of the http response and returns an appropriate error(or not). 
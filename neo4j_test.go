@@ -0,0 +1,158 @@
+package neo4j
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fake neo4j server answering GET, PUT and DELETE on nodes, it records every request it gets
+type testServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests map[string]int // "METHOD path" -> times received
+}
+
+func newTestServer(t *testing.T) *testServer {
+	srv := &testServer{requests: map[string]int{}}
+	srv.Server = httptest.NewServer(http.HandlerFunc(srv.serve))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// client of a server answering the service root itself and every other request with handler
+func newStubClient(t *testing.T, handler http.HandlerFunc, opts ...Option) *Neo4j {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/db/data" || r.URL.Path == "/db/data/" {
+			base := srv.URL + "/db/data"
+			fmt.Fprintf(w, `{"node": %q, "batch": %q, "cypher": %q}`, base+"/node", base+"/batch", base+"/cypher")
+			return
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	neo, err := NewNeo4j(srv.URL+"/db/data", "", "", opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return neo
+}
+
+// node ids decide the answer: GET returns the node, PUT a 204, DELETE a 204 for odd ids and a 409 for even ones. ids over 1000 don't exist
+func (this *testServer) serve(w http.ResponseWriter, r *http.Request) {
+	this.mu.Lock()
+	this.requests[r.Method+" "+r.URL.Path]++
+	this.mu.Unlock()
+	base := this.URL + "/db/data"
+	if r.URL.Path == "/db/data" || r.URL.Path == "/db/data/" {
+		fmt.Fprintf(w, `{"node": %q, "batch": %q}`, base+"/node", base+"/batch")
+		return
+	}
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/db/data/node/"), "/")
+	id, err := strconv.Atoi(parts[0])
+	if err != nil || id > 1000 {
+		w.WriteHeader(404)
+		return
+	}
+	switch {
+	case r.Method == "GET" && len(parts) == 1:
+		fmt.Fprintf(w, `{"self": "%s/node/%d", "data": {"id": %d}}`, base, id, id)
+	case r.Method == "PUT" && len(parts) == 3 && parts[1] == "properties":
+		w.WriteHeader(204)
+	case r.Method == "DELETE" && len(parts) == 1 && id%2 == 1:
+		w.WriteHeader(204)
+	case r.Method == "DELETE" && len(parts) == 1:
+		w.WriteHeader(409)
+		fmt.Fprint(w, `{"message": "Node has relationships", "exception": "OperationFailedException"}`)
+	default:
+		w.WriteHeader(405)
+	}
+}
+
+// times method was sent to path
+func (this *testServer) received(method string, path string) int {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	return this.requests[method+" "+path]
+}
+
+// run it with go test -race, the requests of every goroutine have to keep their own method and status code
+func TestConcurrentRequests(t *testing.T) {
+	srv := newTestServer(t)
+	neo, err := NewNeo4j(srv.URL+"/db/data", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	const n = 90
+	ctx := context.Background()
+	jobs := make([]func(*Neo4j) error, n)
+	for i := range jobs {
+		id := NodeID(i + 1)
+		jobs[i] = func(shared *Neo4j) error {
+			neo := shared.WithContext(ctx) // a copy per job, sharing the http.Client
+			switch id % 3 {
+			case 0:
+				node, err := neo.GetNode(id)
+				if err != nil {
+					return err
+				}
+				if node.NodeID() != id {
+					return errors.New("got node " + node.NodeID().String() + " for " + id.String())
+				}
+				return nil
+			case 1:
+				return neo.SetPropertyTyped(id, map[string]interface{}{"name": id.String()}, false)
+			}
+			err := neo.DelNode(id)
+			if id%2 == 1 {
+				return err
+			}
+			if !errors.Is(err, ErrConflict) {
+				return fmt.Errorf("deleting node %d: got %v, want ErrConflict", id, err)
+			}
+			var serr *ServerError
+			if !errors.As(err, &serr) || serr.Op != "Neo4j.DelNode" || serr.Method != "DELETE" || serr.StatusCode() != 409 {
+				return fmt.Errorf("deleting node %d: got %v, want a 409 for the DELETE of Neo4j.DelNode", id, err)
+			}
+			return nil
+		}
+	}
+	err = neo.NewBulk(16).Run(jobs...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= n; i++ {
+		path := "/db/data/node/" + strconv.Itoa(i)
+		method := []string{"GET", "PUT", "DELETE"}[i%3]
+		if method == "PUT" {
+			path += "/properties/name"
+		}
+		if got := srv.received(method, path); got != 1 {
+			t.Errorf("%s %s received %d times, want 1", method, path, got)
+		}
+	}
+}
+
+// a 404 mapped to success is an empty result, not a body to parse
+func TestMappedNotFound(t *testing.T) {
+	srv := newTestServer(t)
+	neo, err := NewNeo4j(srv.URL+"/db/data", "", "", MapStatus(404, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	degree, err := neo.GetDegree(1001, DirAll)
+	if err != nil || degree != 0 {
+		t.Errorf("GetDegree: got %d, %v, want 0 and no error", degree, err)
+	}
+	enabled, err := neo.GetAutoIdxStatus("node")
+	if err != nil || enabled {
+		t.Errorf("GetAutoIdxStatus: got %v, %v, want false and no error", enabled, err)
+	}
+}
@@ -0,0 +1,88 @@
+package neo4j
+
+import (
+	"errors"
+	"strconv"
+)
+
+// a path as returned from a traversal or path search
+type Path struct {
+	Start         NodeID
+	End           NodeID
+	Nodes         []NodeID // every node on the path in order, Start first
+	Relationships []RelID  // the relationships between them in order
+	Length        int      // number of relationships
+	Weight        float64  // total cost, only set by weighted algorithms like dijkstra
+}
+
+/*
+Path() returns the Path held in the template and any errors raised as error
+works for both path and fullpath results
+*/
+func (this *NeoTemplate) Path() (*Path, error) {
+	if len(this.Start) < 1 || len(this.End) < 1 || this.Nodes == nil {
+		return nil, errors.New("Template does not hold a path.")
+	}
+	path := new(Path)
+	start, err := idFromURL(this.Start)
+	if err != nil {
+		return nil, err
+	}
+	end, err := idFromURL(this.End)
+	if err != nil {
+		return nil, err
+	}
+	path.Start, path.End = NodeID(start), NodeID(end)
+	path.Weight = this.Weight
+	for _, n := range this.Nodes {
+		id, err := pathElementID(n)
+		if err != nil {
+			return nil, err
+		}
+		path.Nodes = append(path.Nodes, NodeID(id))
+	}
+	for _, r := range this.TRelationships {
+		id, err := pathElementID(r)
+		if err != nil {
+			return nil, err
+		}
+		path.Relationships = append(path.Relationships, RelID(id))
+	}
+	path.Length = len(path.Relationships)
+	if len(this.Length) > 0 {
+		path.Length, err = strconv.Atoi(this.Length)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return path, nil
+}
+
+// id of a node or relationship on a path, either its URL (path) or the whole node/relationship (fullpath)
+func pathElementID(v interface{}) (uint64, error) {
+	switch vv := v.(type) {
+	case string:
+		return idFromURL(vv)
+	case map[string]interface{}:
+		if self, ok := vv["self"].(string); ok {
+			return idFromURL(self)
+		}
+	}
+	return 0, errors.New("Unable to read path element.")
+}
+
+/*
+Paths(templates []*NeoTemplate) returns the paths held in templates in order and any errors raised as error
+converts the result of TraversePath or a path traversal
+*/
+func Paths(templates []*NeoTemplate) ([]*Path, error) {
+	paths := make([]*Path, 0, len(templates))
+	for _, template := range templates {
+		path, err := template.Path()
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
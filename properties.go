@@ -0,0 +1,505 @@
+package neo4j
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// converts values of one Go type to and from something neo4j can store, see RegisterConverter
+type Converter struct {
+	ToNeo   func(v interface{}) (interface{}, error) // gets a value of the registered type, returns a string, number, bool or array of those
+	FromNeo func(v interface{}) (interface{}, error) // gets the decoded property value, returns a value of the registered type
+}
+
+// default for Neo4j.BlobMarker
+const defaultBlobMarker = "base64:"
+
+// how time.Time property values are stored, neo4j has no type of its own for them
+type TimeFormat int
+
+const (
+	TimeISO8601 TimeFormat = iota // RFC 3339 strings with nanoseconds, the same as json.Marshal does
+	TimeMillis                    // milliseconds since the unix epoch
+)
+
+/*
+GetPropertyValue(node id uint, name string) returns the decoded property value and any errors raised as error
+integers come back as int64, other numbers as float64, then bool, string or []interface{} for arrays
+strings carrying the BlobMarker come back as []byte
+*/
+func (this *Neo4j) GetPropertyValue(id NodeID, name string) (interface{}, error) {
+	body, err := this.GetProperty(id, name)
+	if err != nil {
+		return nil, err
+	}
+	v, err := decodeValue(body)
+	if err != nil {
+		return nil, err
+	}
+	return this.fromNeo(v)
+}
+
+/*
+GetPropertyValues(node id uint) returns every property of the node decoded like GetPropertyValue and any errors raised as error
+*/
+func (this *Neo4j) GetPropertyValues(id NodeID) (map[string]interface{}, error) {
+	node, err := this.nodeURLs(id)
+	if err != nil {
+		return nil, err
+	}
+	body, resp, err := this.send("Neo4j.GetPropertyValues", "GET", node.Properties, "")
+	if err != nil {
+		return nil, err
+	}
+	err = this.statusError("Neo4j.GetPropertyValues", map[int]error{}, resp, body)
+	if err != nil {
+		return nil, err
+	}
+	if this.ignored(resp.StatusCode) {
+		return map[string]interface{}{}, nil
+	}
+	values, err := decodeValues(body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range values {
+		values[k], err = this.fromNeo(v)
+		if err != nil {
+			return nil, errors.New("Property " + k + ": " + err.Error())
+		}
+	}
+	if len(this.Flatten) > 0 {
+		return UnflattenProperties(values, this.Flatten), nil
+	}
+	return values, nil
+}
+
+/*
+GetPropertyAs(node id uint, name string, target interface{}) returns any errors raised as error
+decodes the property into the variable target points to, see FromNeo
+*/
+func (this *Neo4j) GetPropertyAs(id NodeID, name string, target interface{}) error {
+	v, err := this.GetPropertyValue(id, name)
+	if err != nil {
+		return err
+	}
+	return this.FromNeo(v, target)
+}
+
+/*
+GetPropertyInt(node id uint, name string) returns the property as int64 and any errors raised as error
+strings holding a number, as stored by SetProperty, are parsed
+*/
+func (this *Neo4j) GetPropertyInt(id NodeID, name string) (int64, error) {
+	v, err := this.GetPropertyValue(id, name)
+	if err != nil {
+		return 0, err
+	}
+	switch vv := v.(type) {
+	case int64:
+		return vv, nil
+	case float64:
+		return int64(vv), nil
+	case string:
+		return strconv.ParseInt(strings.TrimSpace(vv), 10, 64)
+	}
+	return 0, errors.New("Property " + name + " is not a number.")
+}
+
+/*
+GetPropertyFloat(node id uint, name string) returns the property as float64 and any errors raised as error
+strings holding a number, as stored by SetProperty, are parsed
+*/
+func (this *Neo4j) GetPropertyFloat(id NodeID, name string) (float64, error) {
+	v, err := this.GetPropertyValue(id, name)
+	if err != nil {
+		return 0, err
+	}
+	switch vv := v.(type) {
+	case int64:
+		return float64(vv), nil
+	case float64:
+		return vv, nil
+	case string:
+		return strconv.ParseFloat(strings.TrimSpace(vv), 64)
+	}
+	return 0, errors.New("Property " + name + " is not a number.")
+}
+
+/*
+GetPropertyBool(node id uint, name string) returns the property as bool and any errors raised as error
+strings like "true", as stored by SetProperty, are parsed
+*/
+func (this *Neo4j) GetPropertyBool(id NodeID, name string) (bool, error) {
+	v, err := this.GetPropertyValue(id, name)
+	if err != nil {
+		return false, err
+	}
+	switch vv := v.(type) {
+	case bool:
+		return vv, nil
+	case string:
+		return strconv.ParseBool(strings.TrimSpace(vv))
+	}
+	return false, errors.New("Property " + name + " is not a boolean.")
+}
+
+/*
+GetPropertyString(node id uint, name string) returns the property as string and any errors raised as error
+unlike GetProperty the value is unquoted, other types are formatted
+*/
+func (this *Neo4j) GetPropertyString(id NodeID, name string) (string, error) {
+	v, err := this.GetPropertyValue(id, name)
+	if err != nil {
+		return "", err
+	}
+	if b, ok := v.([]byte); ok {
+		return string(b), nil
+	}
+	return this.propertyString(v), nil
+}
+
+/*
+GetPropertyStringSlice(node id uint, name string) returns an array property as []string and any errors raised as error
+a single value comes back as a slice of one
+*/
+func (this *Neo4j) GetPropertyStringSlice(id NodeID, name string) ([]string, error) {
+	v, err := this.GetPropertyValue(id, name)
+	if err != nil {
+		return nil, err
+	}
+	list, ok := v.([]interface{})
+	if !ok {
+		list = []interface{}{v}
+	}
+	strs := make([]string, len(list))
+	for i, e := range list {
+		strs[i] = this.propertyString(e)
+	}
+	return strs, nil
+}
+
+/*
+GetRelationshipPropertyValue(relationship id uint, name string) returns the decoded property value and any errors raised as error
+see GetPropertyValue for the types returned
+*/
+func (this *Neo4j) GetRelationshipPropertyValue(id RelID, name string) (interface{}, error) {
+	body, err := this.GetRelationshipProperty(id, name)
+	if err != nil {
+		return nil, err
+	}
+	v, err := decodeValue(body)
+	if err != nil {
+		return nil, err
+	}
+	return this.fromNeo(v)
+}
+
+// decodes a single json value keeping integers as int64
+func decodeValue(s string) (interface{}, error) {
+	var v interface{}
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber() // float64 would lose precision on large integers
+	err := dec.Decode(&v)
+	if err != nil {
+		return nil, err
+	}
+	return normalizeNumbers(v), nil
+}
+
+// decodes a json object of properties keeping integers as int64
+func decodeValues(s string) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	if len(strings.TrimSpace(s)) < 1 { // 204, no properties
+		return values, nil
+	}
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+	err := dec.Decode(&values)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range values {
+		values[k] = normalizeNumbers(v)
+	}
+	return values, nil
+}
+
+// turns json.Number values into int64 or float64
+func normalizeNumbers(v interface{}) interface{} {
+	switch vv := v.(type) {
+	case json.Number:
+		if i, err := vv.Int64(); err == nil {
+			return i
+		}
+		f, _ := vv.Float64()
+		return f
+	case []interface{}:
+		for i := range vv {
+			vv[i] = normalizeNumbers(vv[i])
+		}
+	case map[string]interface{}:
+		for k := range vv {
+			vv[k] = normalizeNumbers(vv[k])
+		}
+	}
+	return v
+}
+
+/*
+GetPropertyTime(node id uint, name string) returns the property converted to time.Time and any errors raised as error
+understands values stored in either TimeFormat
+*/
+func (this *Neo4j) GetPropertyTime(id NodeID, name string) (time.Time, error) {
+	v, err := this.GetPropertyValue(id, name)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return this.Time(v)
+}
+
+/*
+Time(v interface{}) returns a property value as read from neo4j converted to time.Time and any errors raised as error
+numbers are taken as epoch milliseconds, strings as RFC 3339
+*/
+func (this *Neo4j) Time(v interface{}) (time.Time, error) {
+	switch vv := v.(type) {
+	case time.Time:
+		return vv, nil
+	case int64:
+		return time.Unix(0, vv*int64(time.Millisecond)), nil
+	case float64:
+		return time.Unix(0, int64(vv)*int64(time.Millisecond)), nil
+	case string:
+		if ms, err := strconv.ParseInt(vv, 10, 64); err == nil {
+			return time.Unix(0, ms*int64(time.Millisecond)), nil
+		}
+		return time.Parse(time.RFC3339Nano, vv)
+	}
+	return time.Time{}, errors.New("Property value is not a time.")
+}
+
+// converts the values of data into something neo4j can store, see toNeo
+func (this *Neo4j) encodeProperties(data map[string]interface{}) (map[string]interface{}, error) {
+	if data == nil {
+		return nil, nil
+	}
+	if len(this.Flatten) > 0 {
+		data = FlattenProperties(data, this.Flatten)
+	}
+	encoded := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		value, err := this.toNeo(v)
+		if err != nil {
+			return nil, errors.New("Property " + k + ": " + err.Error())
+		}
+		encoded[k] = value
+	}
+	return encoded, nil
+}
+
+/*
+FlattenProperties(data map[string]interface{}, sep string) returns data with nested maps replaced by their values under joined keys
+ie: {"address": {"city": "Leeds"}} with sep "." becomes {"address.city": "Leeds"}, neo4j can't store maps as property values
+*/
+func FlattenProperties(data map[string]interface{}, sep string) map[string]interface{} {
+	flat := make(map[string]interface{}, len(data))
+	flattenInto(flat, "", data, sep)
+	return flat
+}
+
+// copies the values of data into flat, keys prefixed with prefix
+func flattenInto(flat map[string]interface{}, prefix string, data map[string]interface{}, sep string) {
+	for k, v := range data {
+		switch vv := v.(type) {
+		case map[string]interface{}:
+			flattenInto(flat, prefix+k+sep, vv, sep)
+		case map[string]string:
+			for sk, sv := range vv {
+				flat[prefix+k+sep+sk] = sv
+			}
+		default:
+			flat[prefix+k] = v
+		}
+	}
+}
+
+/*
+UnflattenProperties(data map[string]interface{}, sep string) returns data with keys containing sep nested back into maps, the reverse of FlattenProperties
+when a key is both a value and a prefix, ie: "a" and "a.b", the plain value wins and "a.b" is kept as is
+*/
+func UnflattenProperties(data map[string]interface{}, sep string) map[string]interface{} {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys) // a prefix sorts before the keys it prefixes, so plain values are placed first
+	nested := make(map[string]interface{}, len(data))
+	for _, k := range keys {
+		parts := strings.Split(k, sep)
+		m := nested
+		ok := true
+		for _, p := range parts[:len(parts)-1] {
+			next, exists := m[p]
+			if !exists {
+				next = map[string]interface{}{}
+				m[p] = next
+			}
+			child, isMap := next.(map[string]interface{})
+			if !isMap {
+				ok = false
+				break
+			}
+			m = child
+		}
+		if ok {
+			m[parts[len(parts)-1]] = data[k]
+		} else {
+			nested[k] = data[k]
+		}
+	}
+	return nested
+}
+
+/*
+RegisterConverter(sample interface{}, conv *Converter)
+registers conv for every value of the same type as sample, ie: RegisterConverter(uuid.UUID{}, ...)
+converters win over the built in handling of time.Time and []byte. register them before the client is shared between goroutines
+*/
+func (this *Neo4j) RegisterConverter(sample interface{}, conv *Converter) {
+	if this.converters == nil {
+		this.converters = map[reflect.Type]*Converter{}
+	}
+	this.converters[reflect.TypeOf(sample)] = conv
+}
+
+/*
+FromNeo(v interface{}, target interface{}) returns any errors raised as error
+stores a property value as read from neo4j into the variable target points to, converting it to the type of that variable
+registered converters are consulted first, then time.Time, []byte and the plain json types
+*/
+func (this *Neo4j) FromNeo(v interface{}, target interface{}) error {
+	ptr := reflect.ValueOf(target)
+	if ptr.Kind() != reflect.Ptr || ptr.IsNil() {
+		return errors.New("Target must be a non nil pointer.")
+	}
+	dst := ptr.Elem()
+	if conv, ok := this.converters[dst.Type()]; ok && conv.FromNeo != nil {
+		converted, err := conv.FromNeo(v)
+		if err != nil {
+			return err
+		}
+		return assignValue(dst, converted)
+	}
+	switch dst.Interface().(type) {
+	case time.Time:
+		t, err := this.Time(v)
+		if err != nil {
+			return err
+		}
+		dst.Set(reflect.ValueOf(t))
+		return nil
+	case []byte:
+		converted, err := this.fromNeo(v)
+		if err != nil {
+			return err
+		}
+		return assignValue(dst, converted)
+	}
+	return assignValue(dst, v)
+}
+
+// sets dst to v, converting between the number types and building slices element by element
+func assignValue(dst reflect.Value, v interface{}) error {
+	if v == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+	src := reflect.ValueOf(v)
+	if src.Type().AssignableTo(dst.Type()) {
+		dst.Set(src)
+		return nil
+	}
+	if dst.Kind() == reflect.Map && src.Kind() == reflect.Map && src.Type().Key().ConvertibleTo(dst.Type().Key()) { // unflattened properties, see Neo4j.Flatten
+		out := reflect.MakeMapWithSize(dst.Type(), src.Len())
+		iter := src.MapRange()
+		for iter.Next() {
+			elem := reflect.New(dst.Type().Elem()).Elem()
+			err := assignValue(elem, iter.Value().Interface())
+			if err != nil {
+				return err
+			}
+			out.SetMapIndex(iter.Key().Convert(dst.Type().Key()), elem)
+		}
+		dst.Set(out)
+		return nil
+	}
+	if dst.Kind() == reflect.Slice && src.Kind() == reflect.Slice {
+		out := reflect.MakeSlice(dst.Type(), src.Len(), src.Len())
+		for i := 0; i < src.Len(); i++ {
+			err := assignValue(out.Index(i), src.Index(i).Interface())
+			if err != nil {
+				return err
+			}
+		}
+		dst.Set(out)
+		return nil
+	}
+	numeric := func(k reflect.Kind) bool {
+		return (k >= reflect.Int && k <= reflect.Float64)
+	}
+	if numeric(src.Kind()) && numeric(dst.Kind()) {
+		dst.Set(src.Convert(dst.Type()))
+		return nil
+	}
+	if src.Kind() == dst.Kind() && src.Type().ConvertibleTo(dst.Type()) { // named types like type Status string
+		dst.Set(src.Convert(dst.Type()))
+		return nil
+	}
+	return errors.New("Unable to convert " + src.Type().String() + " to " + dst.Type().String() + ".")
+}
+
+// converts a single property value, anything not handled here is left to json.Marshal
+func (this *Neo4j) toNeo(v interface{}) (interface{}, error) {
+	if v != nil {
+		if conv, ok := this.converters[reflect.TypeOf(v)]; ok && conv.ToNeo != nil {
+			return conv.ToNeo(v)
+		}
+	}
+	switch vv := v.(type) {
+	case time.Time:
+		if this.TimeFormat == TimeMillis {
+			return vv.UnixNano() / int64(time.Millisecond), nil
+		}
+		return vv.Format(time.RFC3339Nano), nil
+	case *time.Time:
+		if vv == nil {
+			return nil, nil
+		}
+		return this.toNeo(*vv)
+	case []byte:
+		return this.blobMarker() + base64.StdEncoding.EncodeToString(vv), nil
+	}
+	return v, nil
+}
+
+// converts a single decoded property value back, the reverse of toNeo for the types that can be recognised
+func (this *Neo4j) fromNeo(v interface{}) (interface{}, error) {
+	if s, ok := v.(string); ok && strings.HasPrefix(s, this.blobMarker()) {
+		return base64.StdEncoding.DecodeString(s[len(this.blobMarker()):])
+	}
+	return v, nil
+}
+
+func (this *Neo4j) blobMarker() string {
+	if len(this.BlobMarker) > 0 {
+		return this.BlobMarker
+	}
+	return defaultBlobMarker
+}
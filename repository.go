@@ -0,0 +1,110 @@
+package neo4j
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// CRUD on the nodes of one mapped struct type, see Marshal for the mapping
+type Repository[T any] struct {
+	neo   *Neo4j
+	label string
+}
+
+/*
+NewRepository[T](neo *Neo4j) returns a Repository for the struct type T
+T must have a field tagged neo4j:",id", its nodes carry the label of T, see Label
+*/
+func NewRepository[T any](neo *Neo4j) *Repository[T] {
+	return &Repository[T]{neo: neo, label: Label(new(T))}
+}
+
+/*
+Save(entity *T) returns any errors raised as error
+creates or updates the node of entity along with its relationships, see Neo4j.Save
+*/
+func (this *Repository[T]) Save(entity *T) error {
+	return this.neo.Save(entity)
+}
+
+/*
+FindByID(node id uint) returns the entity stored in the node and any errors raised as error
+*/
+func (this *Repository[T]) FindByID(id NodeID) (*T, error) {
+	entity := new(T)
+	err := this.neo.Load(id, entity)
+	if err != nil {
+		return nil, err
+	}
+	return entity, nil
+}
+
+/*
+FindBy(props map[string]interface{}) returns every entity whose node has all the properties in props and any errors raised as error
+no props returns every entity of T, relationship fields are left empty
+*/
+func (this *Repository[T]) FindBy(props map[string]interface{}) ([]*T, error) {
+	props, err := this.neo.encodeProperties(props)
+	if err != nil {
+		return nil, err
+	}
+	params := map[string]interface{}{}
+	where := []string{}
+	for i, k := range this.neo.sortedKeys(props) {
+		p := "p" + strconv.Itoa(i)
+		params[p] = props[k]
+		where = append(where, "n."+this.neo.cypherName(k)+" = {"+p+"}")
+	}
+	query := "MATCH (n:" + this.neo.cypherName(this.label) + ")"
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " RETURN n ORDER BY id(n)"
+	result, err := this.neo.cypher("Repository.FindBy", query, params)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]*T, 0, len(result.Data))
+	for _, row := range result.Data {
+		if len(row) < 1 {
+			continue
+		}
+		node, err := this.neo.cypherTemplate(row[0])
+		if err != nil {
+			return nil, err
+		}
+		entity := new(T)
+		err = this.neo.Unmarshal(node, entity)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, entity)
+	}
+	return list, nil
+}
+
+/*
+Delete(entity *T) returns any errors raised as error
+deletes the node of entity together with its relationships, the entities at the other end are kept
+*/
+func (this *Repository[T]) Delete(entity *T) error {
+	if entity == nil {
+		return errors.New("Entity must not be nil.")
+	}
+	v, err := structValue(entity)
+	if err != nil {
+		return err
+	}
+	for _, f := range entityFields(v.Type()) {
+		if f.ID {
+			id := v.FieldByIndex(f.Index)
+			if id.IsZero() {
+				return errors.New("Entity has not been saved.")
+			}
+			return this.neo.DelNodeForce(NodeID(id.Convert(reflect.TypeOf(uint64(0))).Uint()))
+		}
+	}
+	return errors.New("Entity " + v.Type().Name() + " has no field tagged neo4j:\",id\".")
+}
@@ -0,0 +1,102 @@
+package neo4j
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// how failed requests are sent again, see Retry
+type retryPolicy struct {
+	attempts int
+	backoff  time.Duration
+	jitter   float64
+}
+
+/*
+Retry(attempts int, backoff time.Duration, jitter float64) returns an Option sending idempotent requests up to attempts times
+a GET, PUT or DELETE is sent again when it fails with an error IsTransient accepts or gets a 502, 503 or 504 back. POST requests never are, they may have been applied already
+the wait before the next attempt starts at backoff and doubles every attempt, jitter(0 to 1) is the part of each wait that is randomized so clients don't retry in lockstep
+cancelling the context passed to WithContext stops the retries
+*/
+func Retry(attempts int, backoff time.Duration, jitter float64) Option {
+	return func(neo *Neo4j) error {
+		if attempts < 1 {
+			return errors.New("Retry needs at least 1 attempt.")
+		}
+		if jitter < 0 || jitter > 1 {
+			return errors.New("Retry jitter must be between 0 and 1.")
+		}
+		neo.retry = &retryPolicy{attempts, backoff, jitter}
+		return nil
+	}
+}
+
+// used by Transact unless DeadlockRetry says otherwise
+var defaultDeadlockRetry = &retryPolicy{attempts: 3, backoff: 50 * time.Millisecond, jitter: 0.5}
+
+/*
+DeadlockRetry(attempts int, backoff time.Duration) returns an Option setting how often Transact runs a transaction that failed on a deadlock
+neo4j aborts one of the transactions it finds waiting on each other, running it again usually succeeds. the wait before each run doubles starting at backoff
+defaults to 3 attempts starting at 50ms, 1 attempt turns it off
+*/
+func DeadlockRetry(attempts int, backoff time.Duration) Option {
+	return func(neo *Neo4j) error {
+		if attempts < 1 {
+			return errors.New("DeadlockRetry needs at least 1 attempt.")
+		}
+		neo.deadlock = &retryPolicy{attempts, backoff, 0.5}
+		return nil
+	}
+}
+
+// whether err says the transaction was aborted to break a deadlock
+func isDeadlock(err error) bool {
+	var serr *ServerError
+	if !errors.As(err, &serr) {
+		return false
+	}
+	return strings.HasSuffix(serr.Code, ".DeadlockDetected") || strings.Contains(serr.Exception, "DeadlockDetected")
+}
+
+// whether attempt n of a request, which ended in resp or err, should be sent again
+func (this *retryPolicy) retryable(method string, n int, resp *http.Response, err error) bool {
+	if this == nil || n >= this.attempts {
+		return false
+	}
+	switch method {
+	case "GET", "PUT", "DELETE":
+	default:
+		return false
+	}
+	if err != nil {
+		return IsTransient(err) && !errors.Is(err, ErrCircuitOpen) // an open breaker is meant to fail fast
+	}
+	switch resp.StatusCode {
+	case 502, 503, 504:
+		return true
+	}
+	return false
+}
+
+// waits out the backoff after attempt n, returns the error of ctx when it ends first
+func (this *retryPolicy) wait(ctx context.Context, n int) error {
+	d := this.backoff
+	for i := 1; i < n && d < time.Minute; i++ {
+		d *= 2
+	}
+	if this.jitter > 0 {
+		d -= time.Duration(rand.Float64() * this.jitter * float64(d))
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,69 @@
+package neo4j
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// counts the retries reported by the client
+type retryMetrics struct {
+	mu      sync.Mutex
+	retries map[string]int // "op method" -> retries
+}
+
+func (this *retryMetrics) Request(op string, method string, status int, d time.Duration) {}
+func (this *retryMetrics) Transactions(delta int)                                        {}
+func (this *retryMetrics) Retry(op string, method string) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	this.retries[op+" "+method]++
+}
+
+// GETs are sent again on a 503 until they get an answer or run out of attempts, POSTs never are
+func TestRetry(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		received = map[string]int{}
+	)
+	metrics := &retryMetrics{retries: map[string]int{}}
+	neo := newStubClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		received[r.Method+" "+r.URL.Path]++
+		n := received[r.Method+" "+r.URL.Path]
+		mu.Unlock()
+		if r.Method == "GET" && r.URL.Path == "/db/data/node/1" && n > 2 {
+			fmt.Fprint(w, `{"self": "http://localhost/db/data/node/1", "data": {}}`)
+			return
+		}
+		w.WriteHeader(503)
+	}, Retry(3, time.Millisecond, 0), Instrument(metrics))
+	_, err := neo.GetNode(1) // 503, 503, 200
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = neo.GetNode(2) // 503 three times
+	var serr *ServerError
+	if !errors.As(err, &serr) || serr.StatusCode() != 503 || !IsTransient(err) {
+		t.Errorf("got %v, want the 503 of the last attempt", err)
+	}
+	_, err = neo.CreateNodeTyped(map[string]interface{}{"name": "a"})
+	if !errors.As(err, &serr) || serr.StatusCode() != 503 {
+		t.Errorf("got %v, want the 503 of the only attempt", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	for path, want := range map[string]int{"GET /db/data/node/1": 3, "GET /db/data/node/2": 3, "POST /db/data/node": 1} {
+		if received[path] != want {
+			t.Errorf("%s received %d times, want %d", path, received[path], want)
+		}
+	}
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	if metrics.retries["Neo4j.GetNode GET"] != 4 || len(metrics.retries) != 1 {
+		t.Errorf("got retries %v, want 4 of Neo4j.GetNode", metrics.retries)
+	}
+}
@@ -0,0 +1,333 @@
+package neo4j
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// how often WaitForIndexOnline checks the index state
+const indexPollInterval = 200 * time.Millisecond
+
+// returned by CreateUniqueConstraint when the constraint exists already
+var ErrConstraintExists = errors.New("Constraint already exists.")
+
+// a schema index as returned from neo4j
+type SchemaIndex struct {
+	Label        string   `json:"label"`
+	PropertyKeys []string `json:"property_keys"`
+}
+
+// a schema constraint as returned from neo4j
+type SchemaConstraint struct {
+	Label        string   `json:"label"`
+	Type         string   `json:"type"` // ie: UNIQUENESS
+	PropertyKeys []string `json:"property_keys"`
+}
+
+// everything neo4j knows about the shape of the graph
+type SchemaInfo struct {
+	Labels            []string
+	RelationshipTypes []string
+	PropertyKeys      []string
+	Indexes           []*SchemaIndex
+	Constraints       []*SchemaConstraint
+}
+
+/*
+Schema() returns a SchemaInfo struct and any errors raised as error
+the underlying requests are sent in parallel
+*/
+func (this *Neo4j) Schema() (*SchemaInfo, error) {
+	info := new(SchemaInfo)
+	err := this.NewBulk(5).Run(
+		func(neo *Neo4j) (err error) {
+			info.Labels, err = neo.ListLabels()
+			return
+		},
+		func(neo *Neo4j) (err error) {
+			info.RelationshipTypes, err = neo.ListRelationshipTypes()
+			return
+		},
+		func(neo *Neo4j) (err error) {
+			info.PropertyKeys, err = neo.ListPropertyKeys()
+			return
+		},
+		func(neo *Neo4j) (err error) {
+			info.Indexes, err = neo.allSchemaIndexes("Neo4j.Schema")
+			return
+		},
+		func(neo *Neo4j) (err error) {
+			info.Constraints, err = neo.ListConstraints()
+			return
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+/*
+ListPropertyKeys() returns every property key in use in the database and any errors raised as error
+*/
+func (this *Neo4j) ListPropertyKeys() ([]string, error) {
+	return this.getStrings("Neo4j.ListPropertyKeys", this.endpoint("property_keys", "/propertykeys"), map[int]error{})
+}
+
+/*
+ListConstraints() returns every schema constraint in the database and any errors raised as error
+*/
+func (this *Neo4j) ListConstraints() ([]*SchemaConstraint, error) {
+	body, resp, err := this.send("Neo4j.ListConstraints", "GET", this.endpoint("constraints", "/schema/constraint"), "")
+	if err != nil {
+		return nil, err
+	}
+	err = this.statusError("Neo4j.ListConstraints", map[int]error{}, resp, body)
+	if err != nil {
+		return nil, err
+	}
+	list := []*SchemaConstraint{}
+	err = json.Unmarshal([]byte(body), &list)
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// lists the schema indexes on every label. older servers can only list them per label
+func (this *Neo4j) allSchemaIndexes(op string) ([]*SchemaIndex, error) {
+	body, resp, err := this.send(op, "GET", this.endpoint("indexes", "/schema/index"), "")
+	if err != nil {
+		return nil, err
+	}
+	list := []*SchemaIndex{}
+	if resp.StatusCode == 200 && json.Unmarshal([]byte(body), &list) == nil {
+		return list, nil
+	}
+	labels, err := this.ListLabels()
+	if err != nil {
+		return nil, err
+	}
+	list = list[:0]
+	for _, label := range labels {
+		idx, err := this.ListSchemaIndexes(label)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, idx...)
+	}
+	return list, nil
+}
+
+/*
+CreateSchemaIndex(label string, property string) returns the SchemaIndex struct and any errors raised as error
+*/
+func (this *Neo4j) CreateSchemaIndex(label string, property string) (*SchemaIndex, error) {
+	if len(label) < 1 || len(strings.TrimSpace(property)) < 1 {
+		return nil, errors.New("Label and property must be at least 1 character.")
+	}
+	s, err := json.Marshal(map[string][]string{"property_keys": {strings.TrimSpace(property)}})
+	if err != nil {
+		return nil, errors.New("Unable to Marshal Json data")
+	}
+	body, resp, err := this.send("Neo4j.CreateSchemaIndex", "POST", this.endpoint("indexes", "/schema/index")+"/"+url.PathEscape(label), string(s))
+	if err != nil {
+		return nil, err
+	}
+	errorList := map[int]error{
+		400: errors.New("Invalid data sent."),
+		409: errors.New("Schema index already exists."),
+	}
+	err = this.statusError("Neo4j.CreateSchemaIndex", errorList, resp, body)
+	if err != nil {
+		return nil, err
+	}
+	idx := new(SchemaIndex)
+	err = json.Unmarshal([]byte(body), idx)
+	if err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
+
+/*
+ListSchemaIndexes(label string) returns the schema indexes on label and any errors raised as error
+*/
+func (this *Neo4j) ListSchemaIndexes(label string) ([]*SchemaIndex, error) {
+	if len(label) < 1 {
+		return nil, errors.New("Label must be at least 1 character.")
+	}
+	body, resp, err := this.send("Neo4j.ListSchemaIndexes", "GET", this.endpoint("indexes", "/schema/index")+"/"+url.PathEscape(label), "")
+	if err != nil {
+		return nil, err
+	}
+	errorList := map[int]error{
+		400: errors.New("Invalid data sent."),
+	}
+	err = this.statusError("Neo4j.ListSchemaIndexes", errorList, resp, body)
+	if err != nil {
+		return nil, err
+	}
+	list := []*SchemaIndex{}
+	err = json.Unmarshal([]byte(body), &list)
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+/*
+DropSchemaIndex(label string, property string) returns any errors raised as error
+*/
+func (this *Neo4j) DropSchemaIndex(label string, property string) error {
+	if len(label) < 1 || len(strings.TrimSpace(property)) < 1 {
+		return errors.New("Label and property must be at least 1 character.")
+	}
+	body, resp, err := this.send("Neo4j.DropSchemaIndex", "DELETE", this.endpoint("indexes", "/schema/index")+"/"+url.PathEscape(label)+"/"+url.PathEscape(strings.TrimSpace(property)), "")
+	if err != nil {
+		return err
+	}
+	errorList := map[int]error{
+		404: errors.New("Schema index not found."),
+	}
+	return this.statusError("Neo4j.DropSchemaIndex", errorList, resp, body)
+}
+
+/*
+CreateUniqueConstraint(label string, property string) returns any errors raised as error
+returns an error matching ErrConstraintExists(use errors.Is) when the constraint is there already
+*/
+func (this *Neo4j) CreateUniqueConstraint(label string, property string) error {
+	if len(label) < 1 || len(strings.TrimSpace(property)) < 1 {
+		return errors.New("Label and property must be at least 1 character.")
+	}
+	s, err := json.Marshal(map[string][]string{"property_keys": {strings.TrimSpace(property)}})
+	if err != nil {
+		return errors.New("Unable to Marshal Json data")
+	}
+	body, resp, err := this.send("Neo4j.CreateUniqueConstraint", "POST", this.endpoint("constraints", "/schema/constraint")+"/"+url.PathEscape(label)+"/uniqueness", string(s))
+	if err != nil {
+		return err
+	}
+	errorList := map[int]error{
+		400: errors.New("Invalid data sent."),
+		409: ErrConstraintExists,
+	}
+	return this.statusError("Neo4j.CreateUniqueConstraint", errorList, resp, body)
+}
+
+/*
+WaitForIndexOnline(label string, property string, timeout time.Duration) returns any errors raised as error
+polls the state of the schema index until it is online. returns an error if the index failed to populate or timeout passed first
+servers without the db.indexes() procedure don't report index state, there the index counts as online once it is listed
+*/
+func (this *Neo4j) WaitForIndexOnline(label string, property string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		state, err := this.indexState("Neo4j.WaitForIndexOnline", label, strings.TrimSpace(property))
+		if err != nil {
+			return err
+		}
+		switch state {
+		case "ONLINE":
+			return nil
+		case "FAILED":
+			return errors.New("Schema index failed to populate.")
+		}
+		if time.Now().After(deadline) {
+			return errors.New("Timed out waiting for schema index to come online.")
+		}
+		select {
+		case <-this.Context().Done():
+			return this.Context().Err()
+		case <-time.After(indexPollInterval):
+		}
+	}
+}
+
+// returns the state of a schema index as reported by db.indexes(), blank when the index isn't there (yet)
+func (this *Neo4j) indexState(op string, label string, property string) (string, error) {
+	result, err := this.cypher(op, "CALL db.indexes()", nil)
+	if err != nil && !procedureMissing(err) {
+		return "", err // a server error or timeout says nothing about the index, don't take it as online
+	}
+	if err != nil { // no procedures on this server, fall back to checking the index exists
+		list, listErr := this.ListSchemaIndexes(label)
+		if listErr != nil {
+			return "", listErr
+		}
+		for _, idx := range list {
+			if len(idx.PropertyKeys) == 1 && idx.PropertyKeys[0] == property {
+				return "ONLINE", nil
+			}
+		}
+		return "", nil
+	}
+	cols := map[string]int{}
+	for i, c := range result.Columns {
+		cols[c] = i
+	}
+	state, ok := cols["state"]
+	if !ok {
+		return "", errors.New("Unable to read schema index state.")
+	}
+	for _, row := range result.Data {
+		if this.indexRowMatches(row, cols, label, property) {
+			s, _ := row[state].(string)
+			return strings.ToUpper(s), nil
+		}
+	}
+	return "", nil
+}
+
+// whether err is the server not knowing a procedure: a ProcedureNotFound, or any 4xx as servers before 3.0 can't parse CALL
+func procedureMissing(err error) bool {
+	var serr *ServerError
+	if !errors.As(err, &serr) {
+		return false
+	}
+	return strings.HasSuffix(serr.Code, ".ProcedureNotFound") || (serr.Status >= 400 && serr.Status < 500)
+}
+
+// checks a db.indexes() row against label & property. the columns differ between server versions
+func (this *Neo4j) indexRowMatches(row []interface{}, cols map[string]int, label string, property string) bool {
+	labels := []string{}
+	props := []string{}
+	for _, c := range []string{"label", "labelsOrTypes", "tokenNames"} {
+		if i, ok := cols[c]; ok {
+			labels = append(labels, this.stringList(row[i])...)
+		}
+	}
+	if i, ok := cols["properties"]; ok {
+		props = this.stringList(row[i])
+	}
+	if len(labels) < 1 || len(props) < 1 {
+		i, ok := cols["description"]
+		if !ok {
+			return false
+		}
+		desc, _ := row[i].(string)
+		return strings.Contains(desc, ":"+label+"("+property+")")
+	}
+	return len(labels) == 1 && labels[0] == label && len(props) == 1 && props[0] == property
+}
+
+// flattens a json string or array of strings
+func (this *Neo4j) stringList(v interface{}) []string {
+	switch vv := v.(type) {
+	case string:
+		return []string{vv}
+	case []interface{}:
+		list := []string{}
+		for _, e := range vv {
+			if s, ok := e.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	}
+	return nil
+}
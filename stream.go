@@ -0,0 +1,125 @@
+package neo4j
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+)
+
+// hands out the templates of a json array response one at a time as they are decoded, see Traversal.Stream
+type TemplateIterator struct {
+	neo  *Neo4j
+	body io.ReadCloser
+	dec  *json.Decoder
+	tmpl *NeoTemplate
+	err  error
+}
+
+/*
+Stream(return type ReturnType) returns a TemplateIterator over the results of the traversal
+results are decoded while the response comes in instead of all at once, so large traversals don't have to fit in memory:
+
+	it := neo.TraversalFrom(id).MaxDepth(10).Stream(neo4j.ReturnNode)
+	defer it.Close()
+	for it.Next() {
+		node := it.Template()
+	}
+	if it.Err() != nil { ... }
+*/
+func (this *Traversal) Stream(returnType ReturnType) *TemplateIterator {
+	it := &TemplateIterator{neo: this.neo}
+	if this.err != nil {
+		it.err = this.err
+		return it
+	}
+	returnType, err := returnType.check()
+	if err != nil {
+		it.err = err
+		return it
+	}
+	it.err = it.open("Traversal.Stream", this.start, returnType, this.description())
+	return it
+}
+
+// sends the traversal and reads up to the start of the result array
+func (this *TemplateIterator) open(op string, id NodeID, returnType ReturnType, j map[string]interface{}) error {
+	node, err := this.neo.nodeURLs(id)
+	if err != nil {
+		return err
+	}
+	s, err := json.Marshal(j)
+	if err != nil {
+		return errors.New("Unable to Marshal Json data")
+	}
+	resp, err := this.neo.do(op, "POST", strings.Replace(node.Traverse, "{returnType}", string(returnType), 1), string(s))
+	if err != nil {
+		return err
+	}
+	this.body = resp.Body
+	errorList := map[int]error{
+		404: errors.New("Node not found."),
+	}
+	err = this.neo.responseError(op, errorList, resp)
+	if err != nil || this.neo.ignored(resp.StatusCode) { // see MapStatus, no templates at all
+		return err
+	}
+	this.dec = json.NewDecoder(resp.Body)
+	t, err := this.dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := t.(json.Delim); !ok || d != '[' {
+		return errors.New("Expected a json array of results.")
+	}
+	return nil
+}
+
+/*
+Next() returns whether there is another template, decoding it from the response
+*/
+func (this *TemplateIterator) Next() bool {
+	this.tmpl = nil
+	if this.err != nil || this.dec == nil {
+		return false
+	}
+	if !this.dec.More() {
+		this.Close() // all read, nothing left to release
+		return false
+	}
+	m := map[string]interface{}{}
+	this.err = this.dec.Decode(&m)
+	if this.err != nil {
+		return false
+	}
+	this.tmpl, this.err = this.neo.unmarshalNode(m)
+	return this.err == nil
+}
+
+/*
+Template() returns the current template
+*/
+func (this *TemplateIterator) Template() *NeoTemplate {
+	return this.tmpl
+}
+
+/*
+Err() returns the error that stopped the iteration, if any
+*/
+func (this *TemplateIterator) Err() error {
+	return this.err
+}
+
+/*
+Close() returns any errors raised as error
+releases the connection, needed when stopping before Next() returned false
+*/
+func (this *TemplateIterator) Close() error {
+	if this.body == nil {
+		return nil
+	}
+	body := this.body
+	this.body = nil
+	this.dec = nil
+	return body.Close()
+}
@@ -0,0 +1,344 @@
+package neo4j
+
+import (
+	"errors"
+	"strings"
+)
+
+// order nodes are visited in by a traversal
+type TraversalOrder string
+
+const (
+	DepthFirst   TraversalOrder = "depth_first"
+	BreadthFirst TraversalOrder = "breadth_first"
+)
+
+// what a traversal may visit more than once
+type Uniqueness string
+
+const (
+	NodeGlobal         Uniqueness = "node_global"         // every node once
+	NodePath           Uniqueness = "node_path"           // a node once per path
+	NodeRecent         Uniqueness = "node_recent"         // a node once among the recently visited
+	RelationshipGlobal Uniqueness = "relationship_global" // every relationship once
+	RelationshipPath   Uniqueness = "relationship_path"   // a relationship once per path
+	RelationshipRecent Uniqueness = "relationship_recent" // a relationship once among the recently visited
+	NodeLevel          Uniqueness = "node_level"          // a node once per depth
+	RelationshipLevel  Uniqueness = "relationship_level"  // a relationship once per depth
+	NoUniqueness       Uniqueness = "none"
+)
+
+// decides where a traversal stops going deeper, build it with PruneNone or PruneJavascript
+type PruneEvaluator struct {
+	Language string `json:"language"`       // builtin or javascript
+	Name     string `json:"name,omitempty"` // builtin evaluators only
+	Body     string `json:"body,omitempty"` // javascript only
+}
+
+/*
+PruneNone() returns the builtin PruneEvaluator that never prunes, MaxDepth still applies
+*/
+func PruneNone() *PruneEvaluator {
+	return &PruneEvaluator{Language: "builtin", Name: "none"}
+}
+
+/*
+PruneJavascript(body string) returns a PruneEvaluator running body for every position, the traversal goes no deeper where it returns true
+ie: "position.endNode().getProperty('date') > 1234567;"
+*/
+func PruneJavascript(body string) *PruneEvaluator {
+	return &PruneEvaluator{Language: "javascript", Body: body}
+}
+
+// makes sure neo4j understands the evaluator
+func (this *PruneEvaluator) check() error {
+	return checkEvaluator("prune evaluator", this.Language, this.Name, this.Body, "none")
+}
+
+// decides which of the positions a traversal visits end up in the result, build it with FilterAll, FilterAllButStartNode or FilterJavascript
+type ReturnFilter struct {
+	Language string `json:"language"`       // builtin or javascript
+	Name     string `json:"name,omitempty"` // builtin filters only
+	Body     string `json:"body,omitempty"` // javascript only
+}
+
+// names of the builtin return filters
+var returnFilterBuiltins = []string{"all", "all_but_start_node"}
+
+/*
+FilterAll() returns the builtin ReturnFilter keeping every position, the start node included
+*/
+func FilterAll() *ReturnFilter {
+	return &ReturnFilter{Language: "builtin", Name: "all"}
+}
+
+/*
+FilterAllButStartNode() returns the builtin ReturnFilter keeping every position except the start node, what neo4j does without a filter
+*/
+func FilterAllButStartNode() *ReturnFilter {
+	return &ReturnFilter{Language: "builtin", Name: "all_but_start_node"}
+}
+
+/*
+FilterJavascript(body string) returns a ReturnFilter running body for every position, it is kept where body returns true
+ie: "position.endNode().getProperty('name').toLowerCase().contains('t');"
+*/
+func FilterJavascript(body string) *ReturnFilter {
+	return &ReturnFilter{Language: "javascript", Body: body}
+}
+
+// makes sure neo4j understands the filter
+func (this *ReturnFilter) check() error {
+	return checkEvaluator("return filter", this.Language, this.Name, this.Body, returnFilterBuiltins...)
+}
+
+// checks the language of a prune evaluator or return filter and that it carries what the language needs
+func checkEvaluator(kind string, language string, name string, body string, builtins ...string) error {
+	switch language {
+	case "builtin":
+		for _, b := range builtins {
+			if normalizeName(name) == b {
+				return nil
+			}
+		}
+		return errors.New("Unknown builtin " + kind + " " + name + ", use one of " + strings.Join(builtins, ", ") + ".")
+	case "javascript":
+		if len(strings.TrimSpace(body)) < 1 {
+			return errors.New("Javascript " + kind + " needs a body.")
+		}
+		return nil
+	}
+	return errors.New("Unsupported " + kind + " language " + language + ", use builtin or javascript.")
+}
+
+// what a traversal returns for each position it keeps
+type ReturnType string
+
+const (
+	ReturnNode         ReturnType = "node"
+	ReturnRelationship ReturnType = "relationship"
+	ReturnPath         ReturnType = "path"
+	ReturnFullPath     ReturnType = "fullpath" // paths with the nodes and relationships on them in full
+)
+
+// lower cases the order and makes sure it is one neo4j knows, blank leaves it to the server
+func (this TraversalOrder) check() (TraversalOrder, error) {
+	o := TraversalOrder(normalizeName(string(this)))
+	switch o {
+	case "", DepthFirst, BreadthFirst:
+		return o, nil
+	}
+	return o, errors.New("Invalid traversal order " + string(this) + ", use depth_first or breadth_first.")
+}
+
+// lower cases the uniqueness and makes sure it is one neo4j knows, blank leaves it to the server
+func (this Uniqueness) check() (Uniqueness, error) {
+	u := Uniqueness(normalizeName(string(this)))
+	switch u {
+	case "", NodeGlobal, NodePath, NodeRecent, RelationshipGlobal, RelationshipPath, RelationshipRecent, NodeLevel, RelationshipLevel, NoUniqueness:
+		return u, nil
+	}
+	return u, errors.New("Invalid uniqueness " + string(this) + ".")
+}
+
+// lower cases the return type and makes sure it is one neo4j knows, blank means ReturnNode
+func (this ReturnType) check() (ReturnType, error) {
+	r := ReturnType(strings.ToLower(strings.TrimSpace(string(this))))
+	switch r {
+	case "":
+		return ReturnNode, nil
+	case ReturnNode, ReturnRelationship, ReturnPath, ReturnFullPath:
+		return r, nil
+	}
+	return r, errors.New("Invalid return type " + string(this) + ", use node, relationship, path or fullpath.")
+}
+
+// lower cases s and replaces spaces with underscores, neo4j reads "depth first" and "depth_first" the same
+func normalizeName(s string) string {
+	return strings.Replace(strings.ToLower(strings.TrimSpace(s)), " ", "_", -1)
+}
+
+// description of a traversal built up step by step, see TraversalFrom
+type Traversal struct {
+	neo           *Neo4j
+	start         NodeID
+	order         TraversalOrder
+	uniqueness    Uniqueness
+	relationships []map[string]string
+	depth         int
+	prune         *PruneEvaluator
+	filter        *ReturnFilter
+	err           error // first invalid argument, returned by the Return* methods
+}
+
+/*
+TraversalFrom(node id uint) returns a Traversal starting at the node, run it with one of the Return* methods:
+
+	nodes, err := neo.TraversalFrom(id).Order(neo4j.BreadthFirst).Uniqueness(neo4j.NodePath).Relationship("KNOWS", neo4j.DirOut).MaxDepth(3).ReturnNodes()
+
+without further settings neo4j walks depth first to a depth of 1 following every relationship
+*/
+func (this *Neo4j) TraversalFrom(id NodeID) *Traversal {
+	return &Traversal{neo: this, start: id}
+}
+
+/*
+Order(order TraversalOrder) returns the Traversal
+*/
+func (this *Traversal) Order(order TraversalOrder) *Traversal {
+	order, err := order.check()
+	if err != nil {
+		this.fail(err)
+		return this
+	}
+	this.order = order
+	return this
+}
+
+/*
+Uniqueness(uniqueness Uniqueness) returns the Traversal
+*/
+func (this *Traversal) Uniqueness(uniqueness Uniqueness) *Traversal {
+	uniqueness, err := uniqueness.check()
+	if err != nil {
+		this.fail(err)
+		return this
+	}
+	this.uniqueness = uniqueness
+	return this
+}
+
+/*
+Relationship(relationship type string, direction Direction) returns the Traversal
+call it once per relationship type to follow, a blank type follows every type in direction
+*/
+func (this *Traversal) Relationship(rType string, direction Direction) *Traversal {
+	direction, err := direction.check()
+	if err != nil {
+		this.fail(err)
+		return this
+	}
+	rel := map[string]string{"direction": string(direction)}
+	if rType = strings.TrimSpace(rType); len(rType) > 0 {
+		rel["type"] = rType
+	}
+	this.relationships = append(this.relationships, rel)
+	return this
+}
+
+/*
+MaxDepth(depth int) returns the Traversal
+*/
+func (this *Traversal) MaxDepth(depth int) *Traversal {
+	if depth < 1 {
+		this.fail(errors.New("Max depth must be at least 1."))
+		return this
+	}
+	this.depth = depth
+	return this
+}
+
+/*
+Prune(prune *PruneEvaluator) returns the Traversal
+*/
+func (this *Traversal) Prune(prune *PruneEvaluator) *Traversal {
+	if prune == nil {
+		this.prune = nil
+		return this
+	}
+	err := prune.check()
+	if err != nil {
+		this.fail(err)
+		return this
+	}
+	this.prune = prune
+	return this
+}
+
+/*
+Filter(filter *ReturnFilter) returns the Traversal
+*/
+func (this *Traversal) Filter(filter *ReturnFilter) *Traversal {
+	if filter == nil {
+		this.filter = nil
+		return this
+	}
+	err := filter.check()
+	if err != nil {
+		this.fail(err)
+		return this
+	}
+	this.filter = filter
+	return this
+}
+
+/*
+ReturnNodes() returns array of NeoTemplate structs of the nodes visited and any errors raised as error
+*/
+func (this *Traversal) ReturnNodes() ([]*NeoTemplate, error) {
+	return this.run("Traversal.ReturnNodes", ReturnNode)
+}
+
+/*
+ReturnRelationships() returns array of NeoTemplate structs of the relationships visited and any errors raised as error
+*/
+func (this *Traversal) ReturnRelationships() ([]*NeoTemplate, error) {
+	return this.run("Traversal.ReturnRelationships", ReturnRelationship)
+}
+
+/*
+ReturnPaths() returns the paths walked and any errors raised as error
+*/
+func (this *Traversal) ReturnPaths() ([]*Path, error) {
+	templates, err := this.run("Traversal.ReturnPaths", ReturnPath)
+	if err != nil {
+		return nil, err
+	}
+	return Paths(templates)
+}
+
+/*
+ReturnFullPaths() returns array of NeoTemplate structs of the paths walked including the nodes and relationships on them and any errors raised as error
+*/
+func (this *Traversal) ReturnFullPaths() ([]*NeoTemplate, error) {
+	return this.run("Traversal.ReturnFullPaths", ReturnFullPath)
+}
+
+// keeps the first error
+func (this *Traversal) fail(err error) {
+	if this.err == nil {
+		this.err = err
+	}
+}
+
+// sends the traversal asking for returnType
+func (this *Traversal) run(op string, returnType ReturnType) ([]*NeoTemplate, error) {
+	if this.err != nil {
+		return nil, this.err
+	}
+	return this.neo.traverse(op, this.start, returnType, this.description())
+}
+
+// the traversal description as neo4j takes it, settings left out use the server defaults
+func (this *Traversal) description() map[string]interface{} {
+	j := map[string]interface{}{}
+	if len(this.order) > 0 {
+		j["order"] = this.order
+	}
+	if len(this.uniqueness) > 0 {
+		j["uniqueness"] = this.uniqueness
+	}
+	if len(this.relationships) > 0 {
+		j["relationships"] = this.relationships
+	}
+	if this.depth > 0 {
+		j["max depth"] = this.depth
+	}
+	if this.prune != nil {
+		j["prune evaluator"] = this.prune
+	}
+	if this.filter != nil {
+		j["return filter"] = this.filter
+	}
+	return j
+}
@@ -0,0 +1,195 @@
+package neo4j
+
+import (
+	"io"
+)
+
+// the client with the methods taking ids as plain uint64 like they did before NodeID and RelID, see Uint
+type UintClient struct {
+	neo *Neo4j
+}
+
+/*
+Uint() returns a wrapper of the client whose methods take node and relationship ids as uint64, for code written before NodeID and RelID
+ie: neo.Uint().GetNode(tmpl.ID) instead of neo.GetNode(tmpl.NodeID())
+Deprecated: the plain ids can't stop a relationship id being passed for a node, convert with NodeID() and RelID() instead
+*/
+func (this *Neo4j) Uint() *UintClient {
+	return &UintClient{neo: this}
+}
+
+// converts a list of ids
+func nodeIDs(ids []uint64) []NodeID {
+	list := make([]NodeID, len(ids))
+	for i, id := range ids {
+		list[i] = NodeID(id)
+	}
+	return list
+}
+
+// see Neo4j.GetProperty
+func (this *UintClient) GetProperty(id uint64, name string) (string, error) {
+	return this.neo.GetProperty(NodeID(id), name)
+}
+
+// see Neo4j.GetProperties
+func (this *UintClient) GetProperties(id uint64) (*NeoTemplate, error) {
+	return this.neo.GetProperties(NodeID(id))
+}
+
+// see Neo4j.SetProperty
+func (this *UintClient) SetProperty(id uint64, data map[string]string, replace bool) error {
+	return this.neo.SetProperty(NodeID(id), data, replace)
+}
+
+// see Neo4j.CreateProperty
+func (this *UintClient) CreateProperty(id uint64, data map[string]string, replace bool) error {
+	return this.neo.CreateProperty(NodeID(id), data, replace)
+}
+
+// see Neo4j.DelProperty
+func (this *UintClient) DelProperty(id uint64, s string) error {
+	return this.neo.DelProperty(NodeID(id), s)
+}
+
+// see Neo4j.DelNode
+func (this *UintClient) DelNode(id uint64) error {
+	return this.neo.DelNode(NodeID(id))
+}
+
+// see Neo4j.DelNodeForce
+func (this *UintClient) DelNodeForce(id uint64) error {
+	return this.neo.DelNodeForce(NodeID(id))
+}
+
+// see Neo4j.GetNode
+func (this *UintClient) GetNode(id uint64) (*NeoTemplate, error) {
+	return this.neo.GetNode(NodeID(id))
+}
+
+// see Neo4j.NodeExists
+func (this *UintClient) NodeExists(id uint64) (bool, error) {
+	return this.neo.NodeExists(NodeID(id))
+}
+
+// see Neo4j.GetRelationshipsOnNode
+func (this *UintClient) GetRelationshipsOnNode(id uint64, name string, direction Direction) ([]*NeoTemplate, error) {
+	return this.neo.GetRelationshipsOnNode(NodeID(id), name, direction)
+}
+
+// see Neo4j.GetRelationshipsOfTypes
+func (this *UintClient) GetRelationshipsOfTypes(id uint64, direction Direction, types ...string) ([]*NeoTemplate, error) {
+	return this.neo.GetRelationshipsOfTypes(NodeID(id), direction, types...)
+}
+
+// see Neo4j.GetDegree
+func (this *UintClient) GetDegree(id uint64, direction Direction, types ...string) (int, error) {
+	return this.neo.GetDegree(NodeID(id), direction, types...)
+}
+
+// see Neo4j.GetRelationship
+func (this *UintClient) GetRelationship(id uint64) (*Relationship, error) {
+	return this.neo.GetRelationship(RelID(id))
+}
+
+// see Neo4j.GetRelationshipProperty
+func (this *UintClient) GetRelationshipProperty(id uint64, name string) (string, error) {
+	return this.neo.GetRelationshipProperty(RelID(id), name)
+}
+
+// see Neo4j.GetRelationshipProperties
+func (this *UintClient) GetRelationshipProperties(id uint64) (*NeoTemplate, error) {
+	return this.neo.GetRelationshipProperties(RelID(id))
+}
+
+// see Neo4j.SetRelationship
+func (this *UintClient) SetRelationship(id uint64, data map[string]string) error {
+	return this.neo.SetRelationship(RelID(id), data)
+}
+
+// see Neo4j.DelRelationshipProperty
+func (this *UintClient) DelRelationshipProperty(id uint64, s string) error {
+	return this.neo.DelRelationshipProperty(RelID(id), s)
+}
+
+// see Neo4j.CreateRelationship
+func (this *UintClient) CreateRelationship(src uint64, dst uint64, data map[string]string, rType string) (*Relationship, error) {
+	return this.neo.CreateRelationship(NodeID(src), NodeID(dst), data, rType)
+}
+
+// see Neo4j.CreateUniqueRelationship
+func (this *UintClient) CreateUniqueRelationship(src uint64, dst uint64, data map[string]string, rType string) (*Relationship, bool, error) {
+	return this.neo.CreateUniqueRelationship(NodeID(src), NodeID(dst), data, rType)
+}
+
+// see Neo4j.Traverse
+func (this *UintClient) Traverse(id uint64, returnType string, order string, uniqueness string, relationships map[string]string, depth int, prune map[string]string, filter map[string]string) ([]*NeoTemplate, error) {
+	return this.neo.Traverse(NodeID(id), returnType, order, uniqueness, relationships, depth, prune, filter)
+}
+
+// see Neo4j.TraversePath
+func (this *UintClient) TraversePath(src uint64, dst uint64, relationships map[string]string, depth uint, algo string, paths bool) ([]*NeoTemplate, error) {
+	return this.neo.TraversePath(NodeID(src), NodeID(dst), relationships, depth, algo, paths)
+}
+
+// see Neo4j.CloneNode
+func (this *UintClient) CloneNode(id uint64, includeRelationships bool) (*NeoTemplate, error) {
+	return this.neo.CloneNode(NodeID(id), includeRelationships)
+}
+
+// see Neo4j.DeleteSubgraph
+func (this *UintClient) DeleteSubgraph(root uint64, relTypes []string, maxDepth int) error {
+	return this.neo.DeleteSubgraph(NodeID(root), relTypes, maxDepth)
+}
+
+// see Neo4j.GetLabels
+func (this *UintClient) GetLabels(id uint64) ([]string, error) {
+	return this.neo.GetLabels(NodeID(id))
+}
+
+// see Neo4j.AddLabels
+func (this *UintClient) AddLabels(id uint64, labels ...string) error {
+	return this.neo.AddLabels(NodeID(id), labels...)
+}
+
+// see Neo4j.SetLabels
+func (this *UintClient) SetLabels(id uint64, labels ...string) error {
+	return this.neo.SetLabels(NodeID(id), labels...)
+}
+
+// see Neo4j.RemoveLabel
+func (this *UintClient) RemoveLabel(id uint64, label string) error {
+	return this.neo.RemoveLabel(NodeID(id), label)
+}
+
+// see Neo4j.ExportCypher
+func (this *UintClient) ExportCypher(w io.Writer, depth int, merge bool, ids ...uint64) error {
+	return this.neo.ExportCypher(w, depth, merge, nodeIDs(ids)...)
+}
+
+// see Neo4j.ExportGraphML
+func (this *UintClient) ExportGraphML(w io.Writer, ids ...uint64) error {
+	return this.neo.ExportGraphML(w, nodeIDs(ids)...)
+}
+
+// see Neo4j.GetNodes, the nodes are keyed by uint64
+func (this *UintClient) GetNodes(ids ...uint64) (map[uint64]*NeoTemplate, error) {
+	nodes, err := this.neo.GetNodes(nodeIDs(ids)...)
+	if err != nil {
+		return nil, err
+	}
+	list := make(map[uint64]*NeoTemplate, len(nodes))
+	for id, node := range nodes {
+		list[uint64(id)] = node
+	}
+	return list, nil
+}
+
+// see Neo4j.DelRelationship
+func (this *UintClient) DelRelationship(id ...uint64) error {
+	list := make([]RelID, len(id))
+	for i, r := range id {
+		list[i] = RelID(r)
+	}
+	return this.neo.DelRelationship(list...)
+}
@@ -0,0 +1,69 @@
+package neo4j
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+)
+
+/*
+UseUUIDs(property string, labels ...string) returns any errors raised as error
+from now on every node the client creates gets a random UUID in property: CreateNode and its variants, CreateUniqueNode, MergeNode, Session.CreateNode, CloneNode, Save and Diff
+copies made by CloneNode get a UUID of their own
+a uniqueness constraint on property is created for each of labels so the UUIDs can be looked up fast, pass a blank property to turn it off again
+*/
+func (this *Neo4j) UseUUIDs(property string, labels ...string) error {
+	this.UUIDProperty = property
+	if len(property) < 1 {
+		return nil
+	}
+	for _, label := range labels {
+		err := this.CreateUniqueConstraint(label, property)
+		if err != nil && !errors.Is(err, ErrConstraintExists) {
+			return err
+		}
+	}
+	return nil
+}
+
+// a copy of data to create a node with, holding a UUID when they are switched on. every path creating nodes goes through it or assignUUID
+func (this *Neo4j) newNodeProperties(data map[string]interface{}) (map[string]interface{}, error) {
+	props := make(map[string]interface{}, len(data)+1)
+	for k, v := range data {
+		props[k] = v
+	}
+	_, err := this.assignUUID(props)
+	if err != nil {
+		return nil, err
+	}
+	return props, nil
+}
+
+// sets the UUID property on data when it is switched on and data doesn't hold a UUID yet, returns the UUID or blank
+func (this *Neo4j) assignUUID(data map[string]interface{}) (string, error) {
+	if len(this.UUIDProperty) < 1 {
+		return "", nil
+	}
+	if s, ok := data[this.UUIDProperty].(string); ok && len(s) > 0 {
+		return s, nil
+	}
+	uuid, err := newUUID()
+	if err != nil {
+		return "", err
+	}
+	data[this.UUIDProperty] = uuid
+	return uuid, nil
+}
+
+// random (version 4) UUID in its canonical text form
+func newUUID() (string, error) {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", errors.New("Unable to generate UUID: " + err.Error())
+	}
+	b[6] = b[6]&0x0f | 0x40 // version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+	s := hex.EncodeToString(b)
+	return s[:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:], nil
+}
@@ -0,0 +1,69 @@
+package neo4j
+
+// checks the properties about to be written to a node carrying label, returning an error aborts the write. see RegisterValidator
+// the write returns the error wrapped, errors.Is and errors.As find it
+type Validator func(label string, props map[string]interface{}) error
+
+/*
+RegisterValidator(label string, v Validator)
+runs v before every create or update of a node carrying label, a blank label runs it for every node. on an update props only holds the properties being written
+every method writing node properties runs them, the Session methods and through them ImportCSV and ImportGraphML included, with these exceptions:
+Session.SetProperty only runs the global validators as the labels of a Ref aren't known, IncrementProperty runs none as the new value is only known on the server
+and CreateNodeID, CreateUniqueNode and Session.CreateNode create nodes without labels, so only the global validators apply. relationship properties aren't validated, but for CreateUniqueRelationship running the global validators
+register validators before the client is shared between goroutines
+*/
+func (this *Neo4j) RegisterValidator(label string, v Validator) {
+	if this.validators == nil {
+		this.validators = map[string][]Validator{}
+	}
+	this.validators[label] = append(this.validators[label], v)
+}
+
+// runs the global validators
+func (this *Neo4j) validate(props map[string]interface{}) error {
+	return this.runValidators("", props)
+}
+
+// runs the validators of each of labels
+func (this *Neo4j) validateLabels(labels []string, props map[string]interface{}) error {
+	for _, label := range labels {
+		if len(label) < 1 {
+			continue
+		}
+		err := this.runValidators(label, props)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runs the validators of a node with an unknown set of labels, looking the labels up only when a label has validators
+func (this *Neo4j) validateNode(id NodeID, props map[string]interface{}) error {
+	err := this.validate(props)
+	if err != nil {
+		return err
+	}
+	if len(this.validators) < 1 || (len(this.validators) == 1 && this.validators[""] != nil) {
+		return nil
+	}
+	labels, err := this.GetLabels(id)
+	if err != nil {
+		return err
+	}
+	return this.validateLabels(labels, props)
+}
+
+// runs the validators registered for label, blank for the global ones
+func (this *Neo4j) runValidators(label string, props map[string]interface{}) error {
+	for _, v := range this.validators[label] {
+		err := v(label, props)
+		if err != nil {
+			if len(label) > 0 {
+				return &wrapError{"Validation of " + label + " failed: " + err.Error(), err}
+			}
+			return &wrapError{"Validation failed: " + err.Error(), err}
+		}
+	}
+	return nil
+}
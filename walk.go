@@ -0,0 +1,106 @@
+package neo4j
+
+// called by Walk for every node visited at depth (the start node is 0)
+// returning false keeps the walk from going past node, returning an error stops the walk altogether
+type WalkFunc func(node *NeoTemplate, depth int) (bool, error)
+
+// settings of Walk, the zero value walks breadth first along every relationship without a depth limit
+type WalkOptions struct {
+	Order     TraversalOrder  // DepthFirst or BreadthFirst
+	MaxDepth  int             // nodes further away than this aren't visited, 0 means no limit
+	Direction Direction       // direction of the relationships followed
+	Types     []string        // types of the relationships followed, none follows every type
+	Visited   map[NodeID]bool // nodes not to visit, visited nodes are added. pass the same map to several walks to visit each node only once overall
+}
+
+// a node waiting to be visited
+type walkStep struct {
+	id    NodeID
+	depth int
+}
+
+/*
+Walk(start node id uint, opts *WalkOptions, fn WalkFunc) returns any errors raised as error
+walks the graph client side from start calling fn once per node, for servers without the traversal framework or walks it can't express
+the visited set doubles as cache: each node and its relationships are fetched once per walk. pass nil opts for the defaults
+*/
+func (this *Neo4j) Walk(start NodeID, opts *WalkOptions, fn WalkFunc) error {
+	if opts == nil {
+		opts = new(WalkOptions)
+	}
+	order, err := opts.Order.check()
+	if err != nil {
+		return err
+	}
+	direction, err := opts.Direction.check()
+	if err != nil {
+		return err
+	}
+	visited := opts.Visited
+	if visited == nil {
+		visited = map[NodeID]bool{}
+	}
+	pending := []walkStep{{id: start}}
+	for len(pending) > 0 {
+		var step walkStep
+		if order == DepthFirst {
+			step, pending = pending[len(pending)-1], pending[:len(pending)-1]
+		} else {
+			step, pending = pending[0], pending[1:]
+		}
+		if visited[step.id] {
+			continue
+		}
+		visited[step.id] = true
+		node, err := this.GetNode(step.id)
+		if err != nil {
+			return err
+		}
+		deeper, err := fn(node, step.depth)
+		if err != nil {
+			return err
+		}
+		if !deeper || (opts.MaxDepth > 0 && step.depth >= opts.MaxDepth) {
+			continue
+		}
+		next, err := this.walkNeighbours("Neo4j.Walk", node, direction, opts.Types)
+		if err != nil {
+			return err
+		}
+		if order == DepthFirst { // reversed so the first neighbour is popped first
+			for i := len(next) - 1; i >= 0; i-- {
+				if !visited[next[i]] {
+					pending = append(pending, walkStep{id: next[i], depth: step.depth + 1})
+				}
+			}
+			continue
+		}
+		for _, id := range next {
+			if !visited[id] {
+				pending = append(pending, walkStep{id: id, depth: step.depth + 1})
+			}
+		}
+	}
+	return nil
+}
+
+// ids of the nodes at the other end of the relationships of node, in the order neo4j lists them
+func (this *Neo4j) walkNeighbours(op string, node *NeoTemplate, direction Direction, types []string) ([]NodeID, error) {
+	rels, err := this.relationshipsOf(op, node, direction, types...)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]NodeID, 0, len(rels))
+	for _, r := range rels {
+		rel, err := r.Relationship()
+		if err != nil {
+			return nil, err
+		}
+		if rel.StartID == node.NodeID() {
+			ids = append(ids, rel.EndID)
+		} else {
+			ids = append(ids, rel.StartID)
+		}
+	}
+	return ids, nil
+}